@@ -0,0 +1,101 @@
+package loadenv
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// BindInt64Var 将键绑定到 v，每次重载后更新，热路径上直接读取 v.Load() 而无需加锁或调用 Get
+//
+//	var maxConcurrency atomic.Int64
+//	maxConcurrency.Store(64) // 键未设置时保留的默认值
+//	if err := l.BindInt64Var(&maxConcurrency, "MAX_CONCURRENCY"); err != nil { ... }
+//
+// 键未设置时保持 v 的原值；值无效时首次绑定返回 ValueError，重载时保留原值并记录日志
+func (l *Loader) BindInt64Var(v *atomic.Int64, key string) error {
+	return l.bindKey(key, "integer", int64Setter(l.values, v))
+}
+
+// BindBoolVar 将键绑定到 v，值的写法同 strconv.ParseBool（以及 Config.BoolValues），参见 Loader.BindInt64Var
+func (l *Loader) BindBoolVar(v *atomic.Bool, key string) error {
+	return l.bindKey(key, "boolean", boolSetter(l.values, v))
+}
+
+// BindDurationVar 将键绑定到 v，值的写法同 time.ParseDuration（如 250ms），v 中保存纳秒数
+func (l *Loader) BindDurationVar(v *atomic.Int64, key string) error {
+	return l.bindKey(key, "duration", durationSetter(v))
+}
+
+// BindValueVar 将键经 parse 转换后存入 v，适用于浮点数、切片等其他类型；
+// parse 的结果类型在多次重载之间必须一致（atomic.Value 的要求）
+func (l *Loader) BindValueVar(v *atomic.Value, key string, parse func(string) (any, error)) error {
+	return l.bindKey(key, "value", valueSetter(v, parse))
+}
+
+// BindInt64Var 在默认加载器上绑定，参见 Loader.BindInt64Var；未初始化时只从进程环境变量设置一次
+func BindInt64Var(v *atomic.Int64, key string) error {
+	return bindKeyDefault(key, "integer", int64Setter(defaultValues(), v))
+}
+
+// BindBoolVar 在默认加载器上绑定，参见 Loader.BindBoolVar
+func BindBoolVar(v *atomic.Bool, key string) error {
+	return bindKeyDefault(key, "boolean", boolSetter(defaultValues(), v))
+}
+
+// BindDurationVar 在默认加载器上绑定，参见 Loader.BindDurationVar
+func BindDurationVar(v *atomic.Int64, key string) error {
+	return bindKeyDefault(key, "duration", durationSetter(v))
+}
+
+// BindValueVar 在默认加载器上绑定，参见 Loader.BindValueVar
+func BindValueVar(v *atomic.Value, key string, parse func(string) (any, error)) error {
+	return bindKeyDefault(key, "value", valueSetter(v, parse))
+}
+
+// defaultValues 返回默认加载器的解析选项，未初始化时为 nil
+func defaultValues() *valueParser {
+	if l := std.Load(); l != nil {
+		return l.values
+	}
+	return nil
+}
+
+func int64Setter(vp *valueParser, v *atomic.Int64) func(string) error {
+	return func(s string) error {
+		n, err := vp.parseInt(s, 10, 64)
+		if err == nil {
+			v.Store(n)
+		}
+		return err
+	}
+}
+
+func boolSetter(vp *valueParser, v *atomic.Bool) func(string) error {
+	return func(s string) error {
+		b, err := vp.parseBool(s)
+		if err == nil {
+			v.Store(b)
+		}
+		return err
+	}
+}
+
+func durationSetter(v *atomic.Int64) func(string) error {
+	return func(s string) error {
+		d, err := time.ParseDuration(s)
+		if err == nil {
+			v.Store(int64(d))
+		}
+		return err
+	}
+}
+
+func valueSetter(v *atomic.Value, parse func(string) (any, error)) func(string) error {
+	return func(s string) error {
+		x, err := parse(s)
+		if err == nil {
+			v.Store(x)
+		}
+		return err
+	}
+}
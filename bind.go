@@ -0,0 +1,60 @@
+package loadenv
+
+import "strings"
+
+// onSnapshot 注册一个在每次重载生效后以新快照调用的函数，并立即以当前快照调用一次
+//
+// 函数在重载协程中同步执行，应尽快返回；用于实现各种 Bind 辅助函数
+func (l *Loader) onSnapshot(fn func(snap *Snapshot)) {
+	l.hooksMu.Lock()
+	l.hooks = append(l.hooks, fn)
+	l.hooksMu.Unlock()
+	fn(l.Snapshot())
+}
+
+// runHooks 以 snap 调用所有通过 onSnapshot 注册的函数
+func (l *Loader) runHooks(snap *Snapshot) {
+	l.hooksMu.Lock()
+	hooks := l.hooks
+	l.hooksMu.Unlock()
+	for _, fn := range hooks {
+		fn(snap)
+	}
+}
+
+// bindKey 每次重载后以键的值调用 set，返回首次调用的错误；之后的错误只记录日志，保留原值
+func (l *Loader) bindKey(key, want string, set func(v string) error) error {
+	name, site := registration()
+	l.addDependent(name, site, key)
+	var first error
+	initial := true
+	l.onSnapshot(func(snap *Snapshot) {
+		err := applyKey(snap, key, want, set)
+		if initial {
+			first, initial = err, false
+		} else if err != nil {
+			l.logger.Printf("Binding not updated: %v", err)
+		}
+	})
+	return first
+}
+
+// bindKeyDefault 在默认加载器上绑定键；未初始化时只从进程环境变量设置一次
+func bindKeyDefault(key, want string, set func(v string) error) error {
+	if l := std.Load(); l != nil {
+		return l.bindKey(key, want, set)
+	}
+	return applyKey(nil, key, want, set)
+}
+
+// applyKey 以键的当前值（去掉首尾空白）调用 set，键未设置时不调用
+func applyKey(snap *Snapshot, key, want string, set func(v string) error) error {
+	v, ok := snap.Lookup(key)
+	if !ok {
+		return nil
+	}
+	if err := set(strings.TrimSpace(v)); err != nil {
+		return snap.describe(&ValueError{Key: key, Value: v, Want: want, Err: err})
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package loadenv
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Canary 配置变更的金丝雀检查：重载后的新配置先在本实例上生效，
+// 在 Window 内检查通过才保留，否则自动恢复为重载前的配置
+type Canary struct {
+	Check    func(ctx context.Context, snap *Snapshot) error // 检查新配置是否可用，返回 nil 表示通过；ctx 在窗口结束或加载器关闭时取消
+	Window   time.Duration                                   // 等待检查通过的时长，默认 30 秒
+	Interval time.Duration                                   // 检查失败后重试的间隔，默认 1 秒
+}
+
+// CanaryError 金丝雀检查未在窗口内通过，新配置已被回滚
+type CanaryError struct {
+	Checksum string // 被回滚的配置版本
+	Err      error  // 最后一次检查的错误
+}
+
+func (e *CanaryError) Error() string {
+	return fmt.Sprintf("loadenv: canary check failed for config %s, rolled back: %v", e.Checksum[:12], e.Err)
+}
+
+func (e *CanaryError) Unwrap() error { return e.Err }
+
+// HTTPProbe 返回一个请求 url 的金丝雀检查，响应状态码为 2xx 时通过
+func HTTPProbe(url string) func(ctx context.Context, snap *Snapshot) error {
+	return func(ctx context.Context, _ *Snapshot) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return err
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		if resp.StatusCode < 200 || resp.StatusCode > 299 {
+			return fmt.Errorf("probe %s: %s", url, resp.Status)
+		}
+		return nil
+	}
+}
+
+// canary 在窗口内反复执行金丝雀检查直到通过，返回最后一次检查的错误
+func (l *Loader) canary(snap *Snapshot) error {
+	c := l.cfg.Canary
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	expired := make(chan struct{})
+	t := l.cfg.Clock.AfterFunc(c.Window, func() {
+		close(expired)
+		cancel()
+	})
+	defer t.Stop()
+
+	l.logger.Printf("Running canary check for config %s (window %s)", snap.ShortChecksum(), c.Window)
+	for {
+		err := c.Check(ctx, snap)
+		if err == nil {
+			l.logger.Printf("Canary check passed for config %s", snap.ShortChecksum())
+			return nil
+		}
+		select {
+		case <-expired:
+			return err
+		default:
+		}
+		if !l.sleep(c.Interval) {
+			return err
+		}
+		select {
+		case <-expired:
+			return err
+		default:
+		}
+	}
+}
+
+// rollbackCanary 金丝雀检查失败时将配置恢复为 before，调用方需持有 reloadMu
+func (l *Loader) rollbackCanary(before, snap *Snapshot, meta ReloadMeta, cause error) error {
+	err := &CanaryError{Checksum: snap.Checksum(), Err: cause}
+	l.logger.Printf("%v", err)
+
+	l.mu.Lock()
+	_, restored, rerr := l.restore(before)
+	l.mu.Unlock()
+	if rerr != nil {
+		l.logger.Printf("Canary rollback failed: %v", rerr)
+		return err
+	}
+	meta.Checksum = restored.Checksum()
+	meta.AppliedAt = l.cfg.Clock.Now()
+	l.finish(snap, restored, meta)
+	return err
+}
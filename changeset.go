@@ -0,0 +1,209 @@
+package loadenv
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ChangeSet 需要一次性生效的一组修改
+type ChangeSet struct {
+	Set    map[string]string // 新增或修改的键
+	Delete []string          // 删除的键，同一个键同时出现在 Set 中时以 Set 为准
+}
+
+// TransactionalSource 支持原子批量写入的来源：其他节点上的监听者要么看到全部修改，要么一个也看不到
+type TransactionalSource interface {
+	Source
+	// Apply 原子地应用 cs
+	Apply(ctx context.Context, cs ChangeSet) error
+}
+
+// FileSource 以另一个 .env 文件作为来源，例如共享卷上由配置发布工具写入的文件
+//
+// 写入时先写临时文件再重命名，读取方（包括其他节点上的 Watch）不会读到写了一半的文件。
+// 多个写入方并发 Apply 时后完成的写入基于它读取时的内容，可能覆盖先完成的修改。
+// 由加载器调用时读取和监听使用 Config.FS，写入总是直接写本地文件系统
+type FileSource struct {
+	Path  string
+	Parse ParseOptions // 读取时的解析选项，通常与 Config.Parse 相同
+}
+
+var (
+	_ WatchableSource     = (*FileSource)(nil)
+	_ WritableSource      = (*FileSource)(nil)
+	_ TransactionalSource = (*FileSource)(nil)
+)
+
+// Name 返回来源名称
+func (s *FileSource) Name() string { return "file:" + s.Path }
+
+// Load 按 Parse 读取文件，变量引用只解析为文件中先前定义的键
+func (s *FileSource) Load(ctx context.Context) (map[string]string, error) {
+	data, err := fs.ReadFile(sourceFS(ctx), s.Path)
+	if err != nil {
+		return nil, err
+	}
+	return parse(data, nil, s.Parse)
+}
+
+// Store 新增或更新 env 中的键
+func (s *FileSource) Store(ctx context.Context, env map[string]string) error {
+	return s.Apply(ctx, ChangeSet{Set: env})
+}
+
+// Apply 原子地修改文件：保留注释、顺序和未修改的行，新增的键按字母顺序追加到末尾；文件不存在时创建
+//
+// 文件按 Parse 解析；Parse 中引号不是语法（DockerEnvFile 或 KeepQuotes）时值原样写为 KEY=VALUE，
+// 按 Parse 读回后不能得到原值的值（例如包含换行）返回错误，文件保持不变
+func (s *FileSource) Apply(ctx context.Context, cs ChangeSet) error {
+	fsys := sourceFS(ctx)
+	mode := os.FileMode(0o600)
+	data, err := fs.ReadFile(fsys, s.Path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+	case err != nil:
+		return err
+	default:
+		if fi, err := fsys.Stat(s.Path); err == nil {
+			mode = fi.Mode().Perm()
+		}
+	}
+	out, err := applyChangeSet(data, cs, s.Parse)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(s.Path, out, mode)
+}
+
+// Watch 监听文件所在目录（重命名替换不会丢失监听），文件变化时调用 notify
+func (s *FileSource) Watch(ctx context.Context, notify func()) error {
+	w, err := sourceFS(ctx).Watch()
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+	if err := w.Add(filepath.Dir(s.Path)); err != nil {
+		return err
+	}
+	name := filepath.Clean(s.Path)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case ev, ok := <-w.Events():
+			if !ok {
+				return nil
+			}
+			if filepath.Clean(ev.Name) == name {
+				notify()
+			}
+		case err, ok := <-w.Errors():
+			if !ok {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+// applyChangeSet 在 .env 内容上应用 cs：修改的键替换其最后一次定义并删除之前的定义，删除的键去掉所有定义
+//
+// 解析器去掉了 BOM 并统一了换行符，写回时恢复：原内容以 BOM 开头时保留 BOM，第一行以 CRLF 结尾时全部使用 CRLF
+func applyChangeSet(src []byte, cs ChangeSet, opts ParseOptions) ([]byte, error) {
+	p := newParser(src, nil)
+	p.opts = opts
+	p.spans = make(map[string][][2]int)
+	if err := p.run(); err != nil {
+		return nil, err
+	}
+
+	// 每个被覆盖的字节范围及替换内容
+	type edit struct {
+		span [2]int
+		text string
+	}
+	var edits []edit
+	for _, key := range cs.Delete {
+		if _, ok := cs.Set[key]; ok {
+			continue
+		}
+		for _, span := range p.spans[key] {
+			edits = append(edits, edit{span: span})
+		}
+	}
+	var added []string
+	lines := make(map[string]string, len(cs.Set))
+	for key, value := range cs.Set {
+		if !validKey(key) {
+			return nil, fmt.Errorf("loadenv: invalid key %q", key)
+		}
+		line, err := formatLine(key, value, opts)
+		if err != nil {
+			return nil, err
+		}
+		lines[key] = line
+		spans := p.spans[key]
+		if len(spans) == 0 {
+			added = append(added, key)
+			continue
+		}
+		for _, span := range spans[:len(spans)-1] {
+			edits = append(edits, edit{span: span})
+		}
+		edits = append(edits, edit{span: spans[len(spans)-1], text: line})
+	}
+	sort.Slice(edits, func(i, j int) bool { return edits[i].span[0] < edits[j].span[0] })
+
+	var b strings.Builder
+	pos := 0
+	for _, e := range edits {
+		if e.span[0] < pos {
+			continue // Delete 中重复的键
+		}
+		b.WriteString(p.src[pos:e.span[0]])
+		b.WriteString(e.text)
+		pos = e.span[1]
+	}
+	b.WriteString(p.src[pos:])
+	if len(added) > 0 && b.Len() > 0 && !strings.HasSuffix(b.String(), "\n") {
+		b.WriteByte('\n')
+	}
+	sort.Strings(added)
+	for _, key := range added {
+		b.WriteString(lines[key])
+	}
+	out := b.String()
+	if line, _, ok := bytes.Cut(src, []byte{'\n'}); ok && bytes.HasSuffix(line, []byte{'\r'}) {
+		out = strings.ReplaceAll(out, "\n", "\r\n")
+	}
+	if bytes.HasPrefix(src, []byte("\ufeff")) {
+		out = "\ufeff" + out
+	}
+	return []byte(out), nil
+}
+
+// formatLine 按 opts 生成 KEY=VALUE 行（含换行）
+//
+// 引号是语法时按需加引号和转义；DockerEnvFile 和 KeepQuotes 中引号不是语法，值原样写出，
+// 此时包含换行或按 opts 读回后不能得到原值（例如 KeepQuotes 中的 # 注释、首尾空白和变量引用）的值返回错误
+func formatLine(key, value string, opts ParseOptions) (string, error) {
+	if !opts.DockerEnvFile && !opts.KeepQuotes {
+		return key + "=" + quoteValue(value) + "\n", nil
+	}
+	if strings.ContainsAny(value, "\r\n") {
+		return "", fmt.Errorf("loadenv: %s: value containing a newline cannot be written without quoting", key)
+	}
+	line := key + "=" + value + "\n"
+	if env, err := parse([]byte(line), nil, opts); err != nil || env[key] != value {
+		return "", fmt.Errorf("loadenv: %s: value cannot be written without quoting", key)
+	}
+	return line, nil
+}
@@ -0,0 +1,69 @@
+package loadenv
+
+import (
+	"context"
+	"maps"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestFileSourceApplyRoundTrip Apply 写入的值按同样的 Parse 读回后与原值一致
+func TestFileSourceApplyRoundTrip(t *testing.T) {
+	set := map[string]string{
+		"PLAIN":  "value",
+		"SPACED": "hello world",
+		"QUOTED": `"already quoted"`,
+		"HASH":   "a #b",
+		"EMPTY":  "",
+	}
+	tests := []struct {
+		name  string
+		parse ParseOptions
+		set   map[string]string
+	}{
+		{"default", ParseOptions{}, maps.Clone(set)},
+		{"keep quotes", ParseOptions{KeepQuotes: true}, map[string]string{"PLAIN": "value", "SPACED": "hello world", "QUOTED": `"already quoted"`, "EMPTY": ""}},
+		{"docker", ParseOptions{DockerEnvFile: true}, maps.Clone(set)},
+	}
+	ctx := context.Background()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), ".env")
+			if err := os.WriteFile(path, []byte("# keep\nPLAIN=old\nOTHER=1\n"), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			s := &FileSource{Path: path, Parse: tt.parse}
+			if err := s.Apply(ctx, ChangeSet{Set: tt.set}); err != nil {
+				t.Fatal(err)
+			}
+			got, err := s.Load(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := maps.Clone(tt.set)
+			want["OTHER"] = "1"
+			if !maps.Equal(got, want) {
+				t.Fatalf("got %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+// TestFileSourceApplyUnquotable 引号不是语法时无法原样写出的值返回错误，文件保持不变
+func TestFileSourceApplyUnquotable(t *testing.T) {
+	for _, parse := range []ParseOptions{{KeepQuotes: true}, {DockerEnvFile: true}} {
+		path := filepath.Join(t.TempDir(), ".env")
+		orig := []byte("A=1\n")
+		if err := os.WriteFile(path, orig, 0o600); err != nil {
+			t.Fatal(err)
+		}
+		s := &FileSource{Path: path, Parse: parse}
+		if err := s.Apply(context.Background(), ChangeSet{Set: map[string]string{"B": "line1\nline2"}}); err == nil {
+			t.Fatalf("%+v: Apply accepted a multiline value", parse)
+		}
+		if data, _ := os.ReadFile(path); string(data) != string(orig) {
+			t.Fatalf("%+v: file changed to %q", parse, data)
+		}
+	}
+}
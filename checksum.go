@@ -0,0 +1,34 @@
+package loadenv
+
+import "net/http"
+
+// ChecksumHeader 携带配置校验和的 HTTP 响应头
+const ChecksumHeader = "X-Config-Checksum"
+
+// Checksum 返回快照内容的稳定哈希，参见 HashEnv
+func (s *Snapshot) Checksum() string {
+	if s == nil {
+		return HashEnv(nil)
+	}
+	return s.checksum
+}
+
+// ShortChecksum 返回校验和的前 12 位，适合用作指标标签或日志字段
+func (s *Snapshot) ShortChecksum() string {
+	return s.Checksum()[:12]
+}
+
+// SetChecksumHeader 将快照校验和写入响应头 X-Config-Checksum
+func (s *Snapshot) SetChecksumHeader(h http.Header) {
+	h.Set(ChecksumHeader, s.Checksum())
+}
+
+// Checksum 返回当前配置的校验和，可用于确认每个实例实际运行的配置版本
+func (l *Loader) Checksum() string {
+	return l.Snapshot().Checksum()
+}
+
+// Checksum 返回默认加载器当前配置的校验和
+func Checksum() string {
+	return current().Checksum()
+}
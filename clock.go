@@ -0,0 +1,21 @@
+package loadenv
+
+import "time"
+
+// Clock 时间源，用于在测试中替换真实时间以模拟防抖窗口
+type Clock interface {
+	Now() time.Time
+	AfterFunc(d time.Duration, f func()) Timer
+}
+
+// Timer 由 Clock.AfterFunc 返回的定时器
+type Timer interface {
+	Stop() bool
+}
+
+// realClock 基于标准库 time 的默认时间源
+type realClock struct{}
+
+func (realClock) Now() time.Time { return time.Now() }
+
+func (realClock) AfterFunc(d time.Duration, f func()) Timer { return time.AfterFunc(d, f) }
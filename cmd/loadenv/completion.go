@@ -0,0 +1,75 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+)
+
+// commandNames 以空格分隔的子命令名，在 init 中生成（直接引用 commands 会形成初始化循环）
+var commandNames string
+
+func init() {
+	names := make([]string, len(commands))
+	for i, c := range commands {
+		names[i] = c.name
+	}
+	commandNames = strings.Join(names, " ")
+}
+
+// runCompletion 输出 shell 补全脚本，子命令之外还会补全 get 的键（通过 loadenv get --list 读取当前目录的 .env）
+//
+//	bash: source <(loadenv completion bash)
+//	zsh:  source <(loadenv completion zsh)
+//	fish: loadenv completion fish | source
+func runCompletion(args []string) error {
+	fs := flag.NewFlagSet("completion", flag.ExitOnError)
+	rest := parseArgs(fs, args)
+	if len(rest) != 1 {
+		return fmt.Errorf("completion: expected bash, zsh or fish")
+	}
+
+	switch rest[0] {
+	case "bash":
+		fmt.Printf(bashCompletion, commandNames)
+	case "zsh":
+		fmt.Printf("autoload -U +X bashcompinit && bashcompinit\n"+bashCompletion, commandNames)
+	case "fish":
+		fmt.Printf(fishCompletion, commandNames)
+	default:
+		return fmt.Errorf("completion: unsupported shell %q", rest[0])
+	}
+	return nil
+}
+
+// bashCompletion 补全子命令和 get 的键；命令行中出现的 -f 文件会传给 get --list
+const bashCompletion = `_loadenv() {
+	local cur=${COMP_WORDS[COMP_CWORD]}
+	if [ "$COMP_CWORD" -eq 1 ]; then
+		COMPREPLY=($(compgen -W "%s" -- "$cur"))
+		return
+	fi
+	local prev=${COMP_WORDS[COMP_CWORD-1]}
+	if [ "$prev" = "-f" ]; then
+		COMPREPLY=($(compgen -f -- "$cur"))
+		return
+	fi
+	if [ "${COMP_WORDS[1]}" = "get" ]; then
+		local files=() i
+		for ((i = 2; i < COMP_CWORD; i++)); do
+			if [ "${COMP_WORDS[i]}" = "-f" ]; then
+				files+=(-f "${COMP_WORDS[i+1]}")
+			fi
+		done
+		COMPREPLY=($(compgen -W "$(loadenv get --list "${files[@]}" 2>/dev/null)" -- "$cur"))
+	fi
+}
+complete -F _loadenv loadenv
+`
+
+const fishCompletion = `complete -c loadenv -f
+complete -c loadenv -n __fish_use_subcommand -a "%s"
+complete -c loadenv -n "__fish_seen_subcommand_from get" -a "(loadenv get --list 2>/dev/null)"
+complete -c loadenv -n "__fish_seen_subcommand_from get" -l raw -d "print the value exactly"
+complete -c loadenv -n "__fish_seen_subcommand_from get" -l json -d "print key, value and source as JSON"
+`
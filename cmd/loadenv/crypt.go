@@ -0,0 +1,76 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"os"
+	"strings"
+
+	"github.com/solorez/loadenv"
+)
+
+// stringList 可重复出现的字符串选项
+type stringList []string
+
+func (s *stringList) String() string { return strings.Join(*s, ",") }
+
+func (s *stringList) Set(v string) error {
+	*s = append(*s, v)
+	return nil
+}
+
+// runEncrypt 使用 age 公钥加密 env 文件，默认输出到 <file>.age
+func runEncrypt(args []string) error {
+	fs := flag.NewFlagSet("encrypt", flag.ExitOnError)
+	var recipients stringList
+	fs.Var(&recipients, "recipient", "age public key (age1...), may be repeated")
+	out := fs.String("o", "", "output file (default <file>.age)")
+	files := parseArgs(fs, args)
+	if len(files) != 1 {
+		return errors.New("encrypt: expected exactly one file")
+	}
+	if len(recipients) == 0 {
+		return errors.New("encrypt: at least one --recipient is required")
+	}
+
+	plaintext, err := os.ReadFile(files[0])
+	if err != nil {
+		return err
+	}
+	ciphertext, err := loadenv.Encrypt(plaintext, recipients...)
+	if err != nil {
+		return err
+	}
+	if *out == "" {
+		*out = files[0] + ".age"
+	}
+	return os.WriteFile(*out, ciphertext, 0o644)
+}
+
+// runDecrypt 使用 age 身份文件解密 env 文件，默认输出到标准输出
+func runDecrypt(args []string) error {
+	fs := flag.NewFlagSet("decrypt", flag.ExitOnError)
+	identity := fs.String("i", "", "age identity file")
+	out := fs.String("o", "", "output file (default stdout)")
+	files := parseArgs(fs, args)
+	if len(files) != 1 {
+		return errors.New("decrypt: expected exactly one file")
+	}
+	if *identity == "" {
+		return errors.New("decrypt: -i identity file is required")
+	}
+
+	ciphertext, err := os.ReadFile(files[0])
+	if err != nil {
+		return err
+	}
+	plaintext, err := loadenv.Decrypt(ciphertext, *identity)
+	if err != nil {
+		return err
+	}
+	if *out == "" {
+		_, err := os.Stdout.Write(plaintext)
+		return err
+	}
+	return os.WriteFile(*out, plaintext, 0o600)
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/solorez/loadenv"
+)
+
+// runDiff 比较两个 env 文件
+//
+// 与 diff(1) 一致：开启 --exit-code 时，有差异返回 1，出错返回 2。
+// --trim、--keep-quotes、--strip-newline 和 --docker 对应 loadenv.ParseOptions，应与应用中 Config.Parse 的设置一致
+func runDiff(args []string) error {
+	fs := flag.NewFlagSet("diff", flag.ExitOnError)
+	redact := fs.Bool("redact", false, "hide values in the output")
+	asJSON := fs.Bool("json", false, "print the diff as JSON")
+	exitCode := fs.Bool("exit-code", false, "exit with status 1 if the files differ")
+	trim := fs.String("trim", "unquoted", "whitespace trimming: unquoted, all or none")
+	keepQuotes := fs.Bool("keep-quotes", false, "keep surrounding quotes as part of the value")
+	stripNewline := fs.Bool("strip-newline", false, "strip trailing newlines from values")
+	docker := fs.Bool("docker", false, "parse like docker run --env-file (no quoting or expansion)")
+	files := parseArgs(fs, args)
+	if len(files) != 2 {
+		return &exitError{code: 2, err: errors.New("diff: expected two files")}
+	}
+	opts := loadenv.ParseOptions{KeepQuotes: *keepQuotes, StripTrailingNewline: *stripNewline, DockerEnvFile: *docker}
+	switch *trim {
+	case "unquoted":
+		opts.Trim = loadenv.TrimUnquoted
+	case "all":
+		opts.Trim = loadenv.TrimAll
+	case "none":
+		opts.Trim = loadenv.TrimNone
+	default:
+		return &exitError{code: 2, err: fmt.Errorf("diff: invalid --trim %q", *trim)}
+	}
+
+	a, err := readEnvFileWith(files[0], false, opts)
+	if err != nil {
+		return &exitError{code: 2, err: err}
+	}
+	b, err := readEnvFileWith(files[1], false, opts)
+	if err != nil {
+		return &exitError{code: 2, err: err}
+	}
+
+	changes := loadenv.Diff(a, b)
+	if *asJSON {
+		err = writeDiffJSON(os.Stdout, changes, *redact)
+	} else {
+		writeDiffText(os.Stdout, changes, *redact)
+	}
+	if err != nil {
+		return &exitError{code: 2, err: err}
+	}
+
+	if *exitCode && len(changes) > 0 {
+		return &exitError{code: 1}
+	}
+	return nil
+}
+
+func writeDiffText(w io.Writer, changes []loadenv.Change, redact bool) {
+	show := func(v string) string {
+		if redact {
+			return loadenv.Redacted
+		}
+		return v
+	}
+	for _, c := range changes {
+		switch c.Type {
+		case loadenv.Added:
+			fmt.Fprintf(w, "+ %s=%s\n", c.Key, show(c.New))
+		case loadenv.Removed:
+			fmt.Fprintf(w, "- %s=%s\n", c.Key, show(c.Old))
+		case loadenv.Modified:
+			fmt.Fprintf(w, "~ %s: %s -> %s\n", c.Key, show(c.Old), show(c.New))
+		}
+	}
+}
+
+// jsonChange JSON 输出中的单个变更，脱敏时省略值
+type jsonChange struct {
+	Key  string  `json:"key"`
+	Type string  `json:"type"`
+	Old  *string `json:"old,omitempty"`
+	New  *string `json:"new,omitempty"`
+}
+
+// jsonChanges 转换为 JSON 输出格式，redact 返回 true 的键省略值
+func jsonChanges(changes []loadenv.Change, redact func(key string) bool) []jsonChange {
+	out := make([]jsonChange, 0, len(changes))
+	for _, c := range changes {
+		jc := jsonChange{Key: c.Key, Type: c.Type.String()}
+		if !redact(c.Key) {
+			if c.Type != loadenv.Added {
+				jc.Old = &c.Old
+			}
+			if c.Type != loadenv.Removed {
+				jc.New = &c.New
+			}
+		}
+		out = append(out, jc)
+	}
+	return out
+}
+
+func writeDiffJSON(w io.Writer, changes []loadenv.Change, redact bool) error {
+	out := jsonChanges(changes, func(string) bool { return redact })
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
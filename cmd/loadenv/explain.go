@@ -0,0 +1,64 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"github.com/solorez/loadenv"
+)
+
+// runExplain 说明一个键的最终值从何而来：每一层的定义、胜出的一层及原因
+//
+// 按加载器的规则加载 -f 指定的文件（以及 --defaults 指定的默认值文件），进程环境变量同样参与优先级比较
+func runExplain(args []string) error {
+	fs := flag.NewFlagSet("explain", flag.ExitOnError)
+	file := fs.String("f", ".env", "env file to load")
+	defaults := fs.String("defaults", "", "defaults file, relative to the env file's directory")
+	asJSON := fs.Bool("json", false, "print the explanation as JSON")
+	rest := parseArgs(fs, args)
+	if len(rest) != 1 {
+		return fmt.Errorf("explain: expected exactly one key")
+	}
+
+	l, err := loadenv.New(loadenv.Config{
+		FilePath:     *file,
+		DefaultsFile: *defaults,
+		Logger:       log.New(io.Discard, "", 0),
+	})
+	if err != nil {
+		return err
+	}
+	defer l.Close()
+
+	e := l.Explain(rest[0])
+	if *asJSON {
+		return json.NewEncoder(os.Stdout).Encode(e)
+	}
+	if !e.Found {
+		return &exitError{code: 1, err: fmt.Errorf("explain: %s is not set", e.Key)}
+	}
+	fmt.Printf("%s=%s\n", e.Key, e.Value)
+	fmt.Printf("  source: %s\n", e.Source)
+	fmt.Printf("  reason: %s\n", e.Reason)
+	if e.Transformed {
+		fmt.Println("  transformed: yes")
+	}
+	fmt.Println("  definitions (lowest to highest precedence):")
+	for _, d := range e.Definitions {
+		fmt.Printf("    %-*s %s\n", width(e.Definitions), d.Source, d.Value)
+	}
+	return nil
+}
+
+// width 返回最长的来源名称长度，用于对齐
+func width(defs []loadenv.Definition) int {
+	n := 0
+	for _, d := range defs {
+		n = max(n, len(d.Source))
+	}
+	return n
+}
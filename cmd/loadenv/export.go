@@ -0,0 +1,68 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/solorez/loadenv"
+)
+
+// runExport 按指定格式导出 env 文件
+//
+// --github-env / --github-output 会把变量追加到 $GITHUB_ENV / $GITHUB_OUTPUT，
+// 并在标准输出打印 ::add-mask:: 命令隐藏敏感值
+func runExport(args []string) error {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	file := fs.String("f", ".env", "env file to export")
+	format := fs.String("format", "dotenv", "output format: dotenv, github, ci, compose, k8s or tfvars")
+	name := fs.String("name", "app-config", "ConfigMap and Secret name for --format k8s")
+	namespace := fs.String("namespace", "", "namespace for --format k8s")
+	githubEnv := fs.Bool("github-env", false, "append to the file named by $GITHUB_ENV")
+	githubOutput := fs.Bool("github-output", false, "append to the file named by $GITHUB_OUTPUT")
+	parseArgs(fs, args)
+
+	env, err := readEnvFile(*file, false)
+	if err != nil {
+		return err
+	}
+
+	if !*githubEnv && !*githubOutput {
+		if loadenv.ExportFormat(*format) == loadenv.FormatK8s {
+			return loadenv.ExportKubernetes(os.Stdout, env, *name, *namespace)
+		}
+		return loadenv.Export(os.Stdout, env, loadenv.ExportFormat(*format))
+	}
+
+	for _, m := range loadenv.GitHubMasks(env) {
+		fmt.Println(m)
+	}
+	if *githubEnv {
+		if err := appendGitHubFile("GITHUB_ENV", env); err != nil {
+			return err
+		}
+	}
+	if *githubOutput {
+		if err := appendGitHubFile("GITHUB_OUTPUT", env); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// appendGitHubFile 将变量追加到环境变量 name 指向的文件
+func appendGitHubFile(name string, env map[string]string) error {
+	path := os.Getenv(name)
+	if path == "" {
+		return fmt.Errorf("export: $%s is not set (not running in GitHub Actions?)", name)
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if err := loadenv.Export(f, env, loadenv.FormatGitHub); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// fileList 可重复的 -f 参数
+type fileList []string
+
+func (f *fileList) String() string { return strings.Join(*f, ",") }
+
+func (f *fileList) Set(v string) error {
+	*f = append(*f, v)
+	return nil
+}
+
+// readLayers 按顺序读取并合并多个 env 文件（后面的覆盖前面的），同时返回每个键来自哪个文件
+func readLayers(files []string) (env, origins map[string]string, err error) {
+	env, origins = make(map[string]string), make(map[string]string)
+	for _, file := range files {
+		layer, err := readEnvFile(file, false)
+		if err != nil {
+			return nil, nil, err
+		}
+		for k, v := range layer {
+			env[k] = v
+			origins[k] = file
+		}
+	}
+	return env, origins, nil
+}
+
+// runGet 输出一个键在分层 env 文件中的最终值
+//
+// 默认输出值和换行；--raw 原样输出值，不加换行；--json 输出键、值和来源文件；
+// --list 列出所有键（用于 shell 补全）。键不存在时退出码为 1
+func runGet(args []string) error {
+	fs := flag.NewFlagSet("get", flag.ExitOnError)
+	var files fileList
+	fs.Var(&files, "f", "env file to read, may be repeated (later files win; default .env)")
+	raw := fs.Bool("raw", false, "print the value exactly, without a trailing newline")
+	asJSON := fs.Bool("json", false, "print key, value and source file as JSON")
+	list := fs.Bool("list", false, "list all keys instead of printing a value")
+	rest := parseArgs(fs, args)
+	if len(files) == 0 {
+		files = fileList{".env"}
+	}
+
+	env, origins, err := readLayers(files)
+	if err != nil {
+		return err
+	}
+	if *list {
+		keys := make([]string, 0, len(env))
+		for k := range env {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			fmt.Println(k)
+		}
+		return nil
+	}
+	if len(rest) != 1 {
+		return fmt.Errorf("get: expected exactly one key")
+	}
+
+	key := rest[0]
+	value, ok := env[key]
+	if !ok {
+		return &exitError{code: 1, err: fmt.Errorf("get: %s is not set", key)}
+	}
+	switch {
+	case *asJSON:
+		enc := json.NewEncoder(os.Stdout)
+		return enc.Encode(struct {
+			Key    string `json:"key"`
+			Value  string `json:"value"`
+			Source string `json:"source"`
+		}{key, value, origins[key]})
+	case *raw:
+		_, err := os.Stdout.WriteString(value)
+		return err
+	}
+	fmt.Println(value)
+	return nil
+}
@@ -0,0 +1,22 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/solorez/loadenv"
+)
+
+// runHash 输出 env 文件内容的稳定哈希，用于部署注解
+func runHash(args []string) error {
+	fs := flag.NewFlagSet("hash", flag.ExitOnError)
+	file := fs.String("f", ".env", "env file to hash")
+	parseArgs(fs, args)
+
+	env, err := readEnvFile(*file, false)
+	if err != nil {
+		return err
+	}
+	fmt.Println(loadenv.HashEnv(env))
+	return nil
+}
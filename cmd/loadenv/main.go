@@ -0,0 +1,115 @@
+// loadenv 命令行工具：同步、比较、合并、导出和查看 env 文件
+//
+// 用法：
+//
+//	loadenv pull [-f .env] [-y] <source>
+//	loadenv push [-f .env] [-y] <source>
+//	loadenv diff [--redact] [--json] [--exit-code] [--trim unquoted|all|none] [--keep-quotes] [--strip-newline] [--docker] a.env b.env
+//	loadenv merge [-o merged.env] base.env overlay.env...
+//	loadenv encrypt --recipient age1... [-o .env.age] .env
+//	loadenv decrypt -i key.txt [-o .env] .env.age
+//	loadenv export [-f .env] [--format dotenv|github|ci|compose|k8s|tfvars] [--github-env] [--github-output]
+//	loadenv hash [-f .env]
+//	loadenv tui [-f .env] [--show-secrets]
+//	loadenv get [-f .env]... [--raw|--json|--list] KEY
+//	loadenv completion bash|zsh|fish
+//	loadenv explain [-f .env] [--defaults .env.defaults] [--json] KEY
+//	loadenv watch [-f .env] [--json] [--show-secrets]
+//	loadenv serve [-f .env] [--addr :8080] [--token-env LOADENV_TOKEN] [--insecure]
+//
+// source 形如 doppler://project/config、infisical://projectID/environment 或 http(s)://（loadenv serve 的地址），
+// 令牌分别从 DOPPLER_TOKEN、INFISICAL_TOKEN 和 LOADENV_TOKEN 环境变量读取
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+)
+
+// command 子命令
+type command struct {
+	name  string
+	usage string
+	run   func(args []string) error
+}
+
+var commands = []command{
+	{"pull", "pull [-f .env] [-y] <source>    download remote config into the local file", runPull},
+	{"push", "push [-f .env] [-y] <source>    upload the local file to the remote source", runPush},
+	{"diff", "diff [--redact] [--json] [--exit-code] [--trim unquoted|all|none] [--keep-quotes] [--strip-newline] [--docker] a.env b.env    compare two env files", runDiff},
+	{"merge", "merge [-o merged.env] base.env overlay.env...    merge env files, later files win", runMerge},
+	{"encrypt", "encrypt --recipient age1... [-o out] file    encrypt an env file with age", runEncrypt},
+	{"decrypt", "decrypt -i key.txt [-o out] file    decrypt an age-encrypted env file", runDecrypt},
+	{"export", "export [-f .env] [--format dotenv|github|ci|compose|k8s|tfvars] [--github-env] [--github-output]    export in another format", runExport},
+	{"hash", "hash [-f .env]    print a stable content hash for deployment annotations", runHash},
+	{"tui", "tui [-f .env] [--show-secrets]    interactive live view of the config", runTUI},
+	{"get", "get [-f .env]... [--raw|--json|--list] KEY    print a key's value from layered env files", runGet},
+	{"completion", "completion bash|zsh|fish    print a shell completion script", runCompletion},
+	{"explain", "explain [-f .env] [--defaults file] [--json] KEY    show where a key's value comes from and why", runExplain},
+	{"watch", "watch [-f .env] [--json] [--show-secrets]    stream change events until interrupted", runWatch},
+	{"serve", "serve [-f .env] [--addr :8080] [--token-env LOADENV_TOKEN] [--insecure]    serve the config to RemoteSource clients", runServe},
+}
+
+// exitError 携带退出码的错误，err 为 nil 时不输出信息
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string {
+	if e.err == nil {
+		return fmt.Sprintf("exit status %d", e.code)
+	}
+	return e.err.Error()
+}
+
+// parseArgs 解析参数，允许选项出现在位置参数之后，返回位置参数
+func parseArgs(fs *flag.FlagSet, args []string) []string {
+	var positional []string
+	for {
+		fs.Parse(args)
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+	for _, c := range commands {
+		if c.name == os.Args[1] {
+			if err := c.run(os.Args[2:]); err != nil {
+				code := 1
+				var ee *exitError
+				if errors.As(err, &ee) {
+					code = ee.code
+					err = ee.err
+				}
+				if err != nil {
+					fmt.Fprintln(os.Stderr, "loadenv:", err)
+				}
+				os.Exit(code)
+			}
+			return
+		}
+	}
+	usage()
+	os.Exit(2)
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: loadenv <command> [arguments]")
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "commands:")
+	for _, c := range commands {
+		fmt.Fprintln(os.Stderr, "  "+c.usage)
+	}
+}
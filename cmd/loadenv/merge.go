@@ -0,0 +1,35 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"os"
+
+	"github.com/solorez/loadenv"
+)
+
+// runMerge 按运行时的覆盖规则合并多个 env 文件，后面的文件覆盖前面的值
+func runMerge(args []string) error {
+	fs := flag.NewFlagSet("merge", flag.ExitOnError)
+	out := fs.String("o", "", "output file (default stdout)")
+	files := parseArgs(fs, args)
+	if len(files) == 0 {
+		return errors.New("merge: expected at least one file")
+	}
+
+	layers := make([]map[string]string, len(files))
+	for i, f := range files {
+		env, err := readEnvFile(f, false)
+		if err != nil {
+			return err
+		}
+		layers[i] = env
+	}
+
+	data := loadenv.Marshal(loadenv.Merge(layers[0], layers[1:]...))
+	if *out == "" {
+		_, err := os.Stdout.Write(data)
+		return err
+	}
+	return os.WriteFile(*out, data, 0o600)
+}
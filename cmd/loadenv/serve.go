@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/solorez/loadenv"
+)
+
+// runServe 以配置服务协议提供 env 文件的合并结果，文件变化后立即生效，
+// 其他实例可以通过 RemoteSource（或 loadenv pull http://...）读取
+//
+// 令牌从 --token-env 指定的环境变量读取（默认 LOADENV_TOKEN），避免出现在进程列表中；
+// 未设置令牌时拒绝启动，除非显式传入 --insecure
+func runServe(args []string) error {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	file := fs.String("f", ".env", "env file to serve")
+	defaults := fs.String("defaults", "", "defaults file, relative to the env file's directory")
+	addr := fs.String("addr", ":8080", "listen address")
+	tokenEnv := fs.String("token-env", "LOADENV_TOKEN", "environment variable holding the bearer token")
+	insecure := fs.Bool("insecure", false, "serve without authentication")
+	parseArgs(fs, args)
+
+	token := os.Getenv(*tokenEnv)
+	if token == "" && !*insecure {
+		return fmt.Errorf("serve: $%s is not set (use --insecure to serve without authentication)", *tokenEnv)
+	}
+
+	l, err := loadenv.New(loadenv.Config{
+		FilePath:     *file,
+		DefaultsFile: *defaults,
+		HotReload:    true,
+		Isolated:     true,
+		ReloadDelay:  200 * time.Millisecond,
+	})
+	if err != nil {
+		return err
+	}
+	defer l.Close()
+
+	mux := http.NewServeMux()
+	mux.Handle("/", l.ConfigHandler(token))
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) { fmt.Fprintln(w, "ok") })
+	srv := &http.Server{Addr: *addr, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		shutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		srv.Shutdown(shutdown)
+	}()
+
+	fmt.Fprintf(os.Stderr, "Serving %s on %s\n", *file, *addr)
+	if err := srv.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/solorez/loadenv"
+)
+
+// runPull 下载远端配置，确认后覆盖本地文件
+func runPull(args []string) error {
+	fs := flag.NewFlagSet("pull", flag.ExitOnError)
+	file := fs.String("f", ".env", "local env file")
+	yes := fs.Bool("y", false, "apply without asking for confirmation")
+	rest := parseArgs(fs, args)
+	if len(rest) != 1 {
+		return errors.New("pull: expected exactly one source")
+	}
+
+	src, err := parseSource(rest[0])
+	if err != nil {
+		return err
+	}
+	local, err := readEnvFile(*file, true)
+	if err != nil {
+		return err
+	}
+	remote, err := src.Load(context.Background())
+	if err != nil {
+		return err
+	}
+
+	if !confirm(os.Stdout, os.Stdin, local, remote, fmt.Sprintf("Write %s", *file), *yes) {
+		return nil
+	}
+	return os.WriteFile(*file, loadenv.Marshal(remote), 0o600)
+}
+
+// runPush 把本地文件写入远端，确认后执行；远端多出的键不会被删除
+func runPush(args []string) error {
+	fs := flag.NewFlagSet("push", flag.ExitOnError)
+	file := fs.String("f", ".env", "local env file")
+	yes := fs.Bool("y", false, "apply without asking for confirmation")
+	rest := parseArgs(fs, args)
+	if len(rest) != 1 {
+		return errors.New("push: expected exactly one source")
+	}
+
+	src, err := parseSource(rest[0])
+	if err != nil {
+		return err
+	}
+	ws, ok := src.(loadenv.WritableSource)
+	if !ok {
+		return fmt.Errorf("push: source %s does not support writes", src.Name())
+	}
+	local, err := readEnvFile(*file, false)
+	if err != nil {
+		return err
+	}
+	remote, err := src.Load(context.Background())
+	if err != nil {
+		return err
+	}
+
+	// 只推送新增和修改的键
+	target := maps.Clone(remote)
+	maps.Copy(target, local)
+	if !confirm(os.Stdout, os.Stdin, remote, target, "Push to "+src.Name(), *yes) {
+		return nil
+	}
+	return ws.Store(context.Background(), local)
+}
+
+// confirm 输出差异并询问是否继续，没有差异时返回 false
+func confirm(w io.Writer, r io.Reader, from, to map[string]string, action string, yes bool) bool {
+	changes := loadenv.Diff(from, to)
+	if len(changes) == 0 {
+		fmt.Fprintln(w, "Already up to date.")
+		return false
+	}
+	for _, c := range changes {
+		fmt.Fprintf(w, "  %s %s\n", changeMark(c.Type), c.Key)
+	}
+	if yes {
+		return true
+	}
+	fmt.Fprintf(w, "%s (%d changes)? [y/N] ", action, len(changes))
+	answer, _ := bufio.NewReader(r).ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true
+	}
+	fmt.Fprintln(w, "Aborted.")
+	return false
+}
+
+func changeMark(t loadenv.ChangeType) string {
+	switch t {
+	case loadenv.Added:
+		return "+"
+	case loadenv.Removed:
+		return "-"
+	}
+	return "~"
+}
+
+// readEnvFile 读取并解析 env 文件，missingOK 为 true 时文件不存在视为空
+func readEnvFile(path string, missingOK bool) (map[string]string, error) {
+	return readEnvFileWith(path, missingOK, loadenv.ParseOptions{})
+}
+
+// readEnvFileWith 与 readEnvFile 相同，按 opts 解析
+func readEnvFileWith(path string, missingOK bool, opts loadenv.ParseOptions) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) && missingOK {
+		return map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return loadenv.ParseBytesWith(data, opts)
+}
+
+// parseSource 解析形如 doppler://project/config 的来源描述
+func parseSource(spec string) (loadenv.Source, error) {
+	u, err := url.Parse(spec)
+	if err != nil {
+		return nil, err
+	}
+	name := strings.Trim(u.Path, "/")
+	switch u.Scheme {
+	case "doppler":
+		return &loadenv.DopplerSource{
+			Token:   os.Getenv("DOPPLER_TOKEN"),
+			Project: u.Host,
+			Config:  name,
+		}, nil
+	case "http", "https":
+		return &loadenv.RemoteSource{URL: spec, Token: os.Getenv("LOADENV_TOKEN")}, nil
+	case "infisical":
+		return &loadenv.InfisicalSource{
+			Token:       os.Getenv("INFISICAL_TOKEN"),
+			ProjectID:   u.Host,
+			Environment: name,
+		}, nil
+	}
+	return nil, fmt.Errorf("unsupported source %q (want doppler://project/config or infisical://project/env)", spec)
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/solorez/loadenv"
+)
+
+// 最近变化的键保持高亮的时长
+const highlightFor = 5 * time.Second
+
+// runTUI 启动交互式配置查看器，文件变化时实时刷新并高亮变化的键
+func runTUI(args []string) error {
+	fs := flag.NewFlagSet("tui", flag.ExitOnError)
+	file := fs.String("f", ".env", "env file to inspect")
+	showSecrets := fs.Bool("show-secrets", false, "start with secret values visible")
+	parseArgs(fs, args)
+
+	l, err := loadenv.New(loadenv.Config{
+		FilePath:    *file,
+		HotReload:   true,
+		ReloadDelay: 200 * time.Millisecond,
+		Logger:      log.New(io.Discard, "", 0),
+	})
+	if err != nil {
+		return err
+	}
+	defer l.Close()
+
+	m := &tuiModel{
+		loader:  l,
+		file:    *file,
+		redact:  !*showSecrets,
+		snap:    l.Snapshot(),
+		changed: make(map[string]time.Time),
+	}
+	_, err = tea.NewProgram(m, tea.WithAltScreen()).Run()
+	return err
+}
+
+type tickMsg time.Time
+
+func tick() tea.Cmd {
+	return tea.Tick(500*time.Millisecond, func(t time.Time) tea.Msg { return tickMsg(t) })
+}
+
+type tuiModel struct {
+	loader *loadenv.Loader
+	file   string
+
+	snap    *loadenv.Snapshot
+	changed map[string]time.Time // 键最近一次变化的时间
+
+	redact    bool
+	searching bool
+	query     string
+	offset    int
+	height    int
+}
+
+func (m *tuiModel) Init() tea.Cmd { return tick() }
+
+func (m *tuiModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.height = msg.Height
+	case tickMsg:
+		if snap := m.loader.Snapshot(); snap != m.snap {
+			now := time.Time(msg)
+			for _, c := range loadenv.Diff(m.snap.Map(), snap.Map()) {
+				m.changed[c.Key] = now
+			}
+			m.snap = snap
+		}
+		return m, tick()
+	case tea.KeyMsg:
+		if m.searching {
+			return m, m.updateSearch(msg)
+		}
+		switch msg.String() {
+		case "q", "ctrl+c":
+			return m, tea.Quit
+		case "/":
+			m.searching = true
+		case "r":
+			m.redact = !m.redact
+		case "up", "k":
+			m.offset = max(m.offset-1, 0)
+		case "down", "j":
+			m.offset++
+		case "esc":
+			m.query = ""
+		}
+	}
+	return m, nil
+}
+
+// updateSearch 处理搜索输入
+func (m *tuiModel) updateSearch(msg tea.KeyMsg) tea.Cmd {
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		return tea.Quit
+	case tea.KeyEnter:
+		m.searching = false
+	case tea.KeyEsc:
+		m.searching = false
+		m.query = ""
+	case tea.KeyBackspace:
+		if m.query != "" {
+			m.query = m.query[:len(m.query)-1]
+		}
+	case tea.KeyRunes:
+		m.query += string(msg.Runes)
+	}
+	m.offset = 0
+	return nil
+}
+
+// visibleKeys 返回符合搜索条件的键
+func (m *tuiModel) visibleKeys() []string {
+	var keys []string
+	q := strings.ToLower(m.query)
+	for _, k := range m.snap.Keys() {
+		if q == "" || strings.Contains(strings.ToLower(k), q) || strings.Contains(strings.ToLower(m.snap.Source(k)), q) {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
+func (m *tuiModel) View() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "\x1b[1mloadenv\x1b[0m %s  checksum %s\n", m.file, m.snap.ShortChecksum())
+
+	keys := m.visibleKeys()
+	width := 3
+	for _, k := range keys {
+		width = max(width, len(k))
+	}
+
+	rows := len(keys)
+	if m.height > 4 {
+		rows = min(rows, m.height-4)
+	}
+	m.offset = min(m.offset, max(len(keys)-rows, 0))
+
+	fmt.Fprintf(&b, "\x1b[4m%-*s  %-40s  %s\x1b[0m\n", width, "KEY", "VALUE", "SOURCE")
+	for _, k := range keys[m.offset : m.offset+rows] {
+		v := strings.ReplaceAll(m.snap.Get(k), "\n", `\n`)
+		if m.redact && loadenv.IsSecretKey(k) {
+			v = loadenv.Redacted
+		}
+		if len(v) > 40 {
+			v = v[:37] + "..."
+		}
+		line := fmt.Sprintf("%-*s  %-40s  %s", width, k, v, m.snap.Source(k))
+		if t, ok := m.changed[k]; ok && time.Since(t) < highlightFor {
+			line = "\x1b[1;33m" + line + "\x1b[0m"
+		}
+		b.WriteString(line + "\n")
+	}
+
+	if m.searching {
+		fmt.Fprintf(&b, "/%s_", m.query)
+	} else {
+		fmt.Fprintf(&b, "\x1b[2m%d keys  / search  r toggle secrets  ↑↓ scroll  q quit\x1b[0m", len(keys))
+	}
+	return b.String()
+}
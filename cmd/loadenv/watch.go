@@ -0,0 +1,92 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/solorez/loadenv"
+)
+
+// watchEvent watch --json 输出的一行
+type watchEvent struct {
+	Time     time.Time    `json:"time"`
+	Checksum string       `json:"checksum"`
+	Op       string       `json:"op,omitempty"`
+	Changes  []jsonChange `json:"changes"`
+}
+
+// runWatch 监听 env 文件，每次变更输出一行
+//
+// 不修改任何进程的环境变量，只报告文件内容的变化；敏感键的值默认省略，--show-secrets 时输出。
+// 收到 SIGINT 或 SIGTERM 时退出
+func runWatch(args []string) error {
+	fs := flag.NewFlagSet("watch", flag.ExitOnError)
+	file := fs.String("f", ".env", "env file to watch")
+	asJSON := fs.Bool("json", false, "print each change event as a JSON line")
+	showSecrets := fs.Bool("show-secrets", false, "include values of secret keys")
+	delay := fs.Duration("delay", 200*time.Millisecond, "debounce delay for file events")
+	parseArgs(fs, args)
+
+	l, err := loadenv.New(loadenv.Config{
+		FilePath:    *file,
+		HotReload:   true,
+		Isolated:    true,
+		ReloadDelay: *delay,
+		Logger:      log.New(io.Discard, "", 0),
+	})
+	if err != nil {
+		return err
+	}
+	defer l.Close()
+
+	sub := l.Subscribe(16, loadenv.Coalesce)
+	defer sub.Close()
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+
+	redact := func(key string) bool { return !*showSecrets && loadenv.IsSecretKey(key) }
+	enc := json.NewEncoder(os.Stdout)
+	for {
+		select {
+		case <-sig:
+			return nil
+		case ev, ok := <-sub.C:
+			if !ok {
+				return nil
+			}
+			if !*asJSON {
+				writeDiffText(os.Stdout, redactChanges(ev.Changes, redact), false)
+				continue
+			}
+			line := watchEvent{
+				Time:     ev.Meta.AppliedAt,
+				Checksum: ev.Snapshot.Checksum(),
+				Changes:  jsonChanges(ev.Changes, redact),
+			}
+			if ev.Meta.Op != 0 {
+				line.Op = ev.Meta.Op.String()
+			}
+			if err := enc.Encode(line); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// redactChanges 将 redact 返回 true 的键的值替换为 ***
+func redactChanges(changes []loadenv.Change, redact func(key string) bool) []loadenv.Change {
+	out := make([]loadenv.Change, len(changes))
+	for i, c := range changes {
+		if redact(c.Key) {
+			c.Old, c.New = loadenv.Redacted, loadenv.Redacted
+		}
+		out[i] = c
+	}
+	return out
+}
@@ -0,0 +1,178 @@
+package loadenv
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Decompressor 把按某种 Content-Encoding 压缩的响应体包装为解压后的数据流
+type Decompressor func(r io.Reader) (io.ReadCloser, error)
+
+var (
+	decompressorsMu sync.RWMutex
+	decompressors   = map[string]Decompressor{
+		"gzip": func(r io.Reader) (io.ReadCloser, error) { return gzip.NewReader(r) },
+		"zstd": newZstdReader,
+	}
+)
+
+// newZstdReader 以单个 goroutine 解压 zstd 数据流，窗口限制为 8 MB，避免在小容器中占用过多内存
+func newZstdReader(r io.Reader) (io.ReadCloser, error) {
+	d, err := zstd.NewReader(r, zstd.WithDecoderConcurrency(1), zstd.WithDecoderMaxWindow(8<<20))
+	if err != nil {
+		return nil, err
+	}
+	return d.IOReadCloser(), nil
+}
+
+// RegisterDecompressor 注册 RemoteSource 可接受的响应编码，同名时覆盖
+//
+// 内置 gzip 和 zstd（github.com/klauspost/compress/zstd），可以注册其他编码（如 br）或替换内置实现
+func RegisterDecompressor(encoding string, fn Decompressor) {
+	decompressorsMu.Lock()
+	decompressors[strings.ToLower(encoding)] = fn
+	decompressorsMu.Unlock()
+}
+
+// acceptEncoding 返回请求中的 Accept-Encoding 值，按名称排序
+func acceptEncoding() string {
+	decompressorsMu.RLock()
+	defer decompressorsMu.RUnlock()
+	names := make([]string, 0, len(decompressors))
+	for name := range decompressors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// decompress 按响应的 Content-Encoding 解压响应体，未压缩时原样返回
+func decompress(resp *http.Response) (io.ReadCloser, error) {
+	encoding := strings.ToLower(strings.TrimSpace(resp.Header.Get("Content-Encoding")))
+	if encoding == "" || encoding == "identity" {
+		return resp.Body, nil
+	}
+	decompressorsMu.RLock()
+	fn := decompressors[encoding]
+	decompressorsMu.RUnlock()
+	if fn == nil {
+		return nil, fmt.Errorf("unsupported Content-Encoding %q", encoding)
+	}
+	return fn(resp.Body)
+}
+
+// acceptsGzip 判断客户端是否接受 gzip 编码的响应
+func acceptsGzip(r *http.Request) bool {
+	for _, part := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if strings.EqualFold(strings.TrimSpace(name), "gzip") && strings.ReplaceAll(params, " ", "") != "q=0" {
+			return true
+		}
+	}
+	return false
+}
+
+// writeRemoteConfig 逐个键写出配置服务的响应体，不在内存中拼出整个文档
+func writeRemoteConfig(w io.Writer, checksum string, env map[string]string) error {
+	keys := make([]string, 0, len(env))
+	for k := range env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	buf := make([]byte, 0, 256)
+	buf = append(buf, `{"checksum":`...)
+	buf = appendJSONString(buf, checksum)
+	buf = append(buf, `,"env":{`...)
+	for i, k := range keys {
+		if i > 0 {
+			buf = append(buf, ',')
+		}
+		buf = appendJSONString(buf, k)
+		buf = append(buf, ':')
+		buf = appendJSONString(buf, env[k])
+		if _, err := w.Write(buf); err != nil {
+			return err
+		}
+		buf = buf[:0]
+	}
+	buf = append(buf, "}}\n"...)
+	_, err := w.Write(buf)
+	return err
+}
+
+// appendJSONString 把 s 编码为 JSON 字符串追加到 buf
+func appendJSONString(buf []byte, s string) []byte {
+	b, _ := json.Marshal(s)
+	return append(buf, b...)
+}
+
+// readRemoteConfig 流式解码配置服务的响应体
+//
+// json.Decoder.Decode 会先把整个值读入内存再解析，对几 MB 的文档在小容器中会造成内存尖峰；
+// 这里逐个读取 env 中的键值对，同一时刻只缓冲一个值
+func readRemoteConfig(r io.Reader) (*remoteConfig, error) {
+	dec := json.NewDecoder(r)
+	if err := expectDelim(dec, '{'); err != nil {
+		return nil, err
+	}
+	cfg := &remoteConfig{Env: map[string]string{}}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch tok {
+		case "checksum":
+			err = dec.Decode(&cfg.Checksum)
+		case "env":
+			err = readEnvObject(dec, cfg.Env)
+		default:
+			var skip json.RawMessage
+			err = dec.Decode(&skip)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return cfg, expectDelim(dec, '}')
+}
+
+// readEnvObject 逐个读取 JSON 对象中的字符串键值对
+func readEnvObject(dec *json.Decoder, env map[string]string) error {
+	if err := expectDelim(dec, '{'); err != nil {
+		return err
+	}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		var value string
+		if err := dec.Decode(&value); err != nil {
+			return fmt.Errorf("env %v: %w", tok, err)
+		}
+		env[tok.(string)] = value
+	}
+	return expectDelim(dec, '}')
+}
+
+// expectDelim 读取下一个标记并确认是指定的分隔符
+func expectDelim(dec *json.Decoder, want json.Delim) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if d, ok := tok.(json.Delim); !ok || d != want {
+		return fmt.Errorf("unexpected JSON token %v, want %v", tok, want)
+	}
+	return nil
+}
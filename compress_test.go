@@ -0,0 +1,52 @@
+package loadenv
+
+import (
+	"bytes"
+	"io"
+	"maps"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestDecompressZstd 内置的 zstd 解压器还原出与原文件相同的内容
+func TestDecompressZstd(t *testing.T) {
+	if !strings.Contains(acceptEncoding(), "zstd") {
+		t.Fatalf("Accept-Encoding %q does not include zstd", acceptEncoding())
+	}
+	compressed, err := os.ReadFile(filepath.Join("testdata", "compress", "basic.env.zst"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	plain, err := os.ReadFile(filepath.Join("testdata", "parser", "basic.env"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := &http.Response{
+		Header: http.Header{"Content-Encoding": {"zstd"}},
+		Body:   io.NopCloser(bytes.NewReader(compressed)),
+	}
+	body, err := decompress(resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer body.Close()
+	data, err := io.ReadAll(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, plain) {
+		t.Fatalf("got %q, want %q", data, plain)
+	}
+
+	env, err := ParseBytes(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := parserFixtures["basic.env"].want; !maps.Equal(env, want) {
+		t.Fatalf("got %q, want %q", env, want)
+	}
+}
@@ -0,0 +1,64 @@
+package loadenv
+
+import (
+	"os"
+	"sort"
+)
+
+// Conflict 由加载器写入的键在进程环境变量中被其他代码修改或删除
+type Conflict struct {
+	Key     string
+	Want    string // 加载器写入的值
+	Got     string // 当前进程环境变量中的值
+	Present bool   // 键是否仍然存在
+}
+
+// CheckConflicts 检查由加载器写入的键是否仍保持写入时的值，返回按键名排序的冲突列表
+func (l *Loader) CheckConflicts() []Conflict {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.conflicts()
+}
+
+// conflicts 调用方需持有 mu
+func (l *Loader) conflicts() []Conflict {
+	var cs []Conflict
+	for key, want := range l.applied {
+		got, ok := os.LookupEnv(key)
+		if ok && got == want {
+			continue
+		}
+		cs = append(cs, Conflict{Key: key, Want: want, Got: got, Present: ok})
+	}
+	sort.Slice(cs, func(i, j int) bool { return cs[i].Key < cs[j].Key })
+	return cs
+}
+
+// reportConflicts 记录冲突并调用 OnConflict
+func (l *Loader) reportConflicts(cs []Conflict) {
+	for _, c := range cs {
+		want, got := c.Want, c.Got
+		if IsSecretKey(c.Key) {
+			want, got = Redacted, Redacted
+		}
+		if c.Present {
+			l.logger.Printf("Conflict: %s was changed outside the loader: %s (loader set: %s)", c.Key, got, want)
+		} else {
+			l.logger.Printf("Conflict: %s was unset outside the loader (loader set: %s)", c.Key, want)
+		}
+		if l.cfg.OnConflict != nil {
+			l.cfg.OnConflict(c)
+		}
+	}
+}
+
+// scheduleConflictCheck 按 ConflictCheckInterval 周期性检查冲突，直到加载器关闭
+func (l *Loader) scheduleConflictCheck() {
+	l.cfg.Clock.AfterFunc(l.cfg.ConflictCheckInterval, func() {
+		if l.isClosed() {
+			return
+		}
+		l.reportConflicts(l.CheckConflicts())
+		l.scheduleConflictCheck()
+	})
+}
@@ -0,0 +1,19 @@
+package loadenv
+
+import "context"
+
+type snapshotKey struct{}
+
+// NewContext 返回携带 snap 的 context，请求范围内的代码通过 FromContext 读取同一个快照，
+// 请求处理期间发生的重载不影响已在处理中的请求
+func NewContext(ctx context.Context, snap *Snapshot) context.Context {
+	return context.WithValue(ctx, snapshotKey{}, snap)
+}
+
+// FromContext 返回 ctx 携带的快照；没有时返回默认加载器的当前快照
+func FromContext(ctx context.Context) *Snapshot {
+	if snap, ok := ctx.Value(snapshotKey{}).(*Snapshot); ok {
+		return snap
+	}
+	return current()
+}
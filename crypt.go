@@ -0,0 +1,80 @@
+package loadenv
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+)
+
+// ErrNoIdentity 环境文件已加密但没有配置解密身份
+var ErrNoIdentity = errors.New("loadenv: file is age-encrypted but no identity file is configured")
+
+// isEncrypted 判断内容是否为 age 加密格式（二进制或 ASCII armor）
+func isEncrypted(data []byte) bool {
+	return bytes.HasPrefix(data, []byte("age-encryption.org/")) ||
+		bytes.HasPrefix(bytes.TrimSpace(data), []byte(armor.Header))
+}
+
+// Encrypt 使用 age 公钥（age1...）加密内容，输出 ASCII armor 格式，便于提交到版本库
+func Encrypt(plaintext []byte, recipients ...string) ([]byte, error) {
+	if len(recipients) == 0 {
+		return nil, errors.New("loadenv: encrypt: no recipients")
+	}
+	rs := make([]age.Recipient, 0, len(recipients))
+	for _, r := range recipients {
+		x, err := age.ParseX25519Recipient(r)
+		if err != nil {
+			return nil, fmt.Errorf("loadenv: encrypt: %w", err)
+		}
+		rs = append(rs, x)
+	}
+
+	var buf bytes.Buffer
+	aw := armor.NewWriter(&buf)
+	w, err := age.Encrypt(aw, rs...)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(plaintext); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	if err := aw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Decrypt 使用 age 身份文件（age-keygen 生成）解密内容，支持二进制和 ASCII armor 格式
+func Decrypt(ciphertext []byte, identityFile string) ([]byte, error) {
+	return decrypt(osFS{}, ciphertext, identityFile)
+}
+
+// decrypt 与 Decrypt 相同，身份文件从 fsys 读取
+func decrypt(fsys FS, ciphertext []byte, identityFile string) ([]byte, error) {
+	f, err := fsys.Open(identityFile)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	ids, err := age.ParseIdentities(f)
+	if err != nil {
+		return nil, fmt.Errorf("loadenv: decrypt: %s: %w", identityFile, err)
+	}
+
+	var src io.Reader = bytes.NewReader(ciphertext)
+	if !bytes.HasPrefix(ciphertext, []byte("age-encryption.org/")) {
+		src = armor.NewReader(bytes.NewReader(bytes.TrimSpace(ciphertext)))
+	}
+	r, err := age.Decrypt(src, ids...)
+	if err != nil {
+		return nil, fmt.Errorf("loadenv: decrypt: %w", err)
+	}
+	return io.ReadAll(r)
+}
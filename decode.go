@@ -0,0 +1,488 @@
+package loadenv
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Decode 按结构体标签将快照中的值解码到 v（指向结构体的指针）
+//
+// 字段通过 env 标签指定键名，不带标签的非结构体字段被忽略：
+//
+//	Port      int           `env:"PORT"`
+//	Timeout   time.Duration `env:"TIMEOUT"`
+//	DB        DBConfig      `env:"DB"`       // 结构体：以 DB_ 作为其字段键名的前缀；不带标签时不加前缀
+//	Endpoints []Endpoint    `env:"ENDPOINT"` // 结构体切片：依次读取 ENDPOINT_0_*、ENDPOINT_1_* …直到某个下标没有任何键
+//	Hosts     []string      `env:"HOSTS"`    // 标量切片：HOSTS 为逗号分隔的列表；未设置时依次读取 HOSTS_0、HOSTS_1 …
+//	Headers   map[string]string `env:"HEADER"` // 映射：所有 HEADER_* 键，映射的键为去掉 HEADER_ 之后的部分；值可以是任意标量类型
+//
+// 标量和标量切片字段可以通过标签指定键未设置时的默认值：
+//
+//	DataDir string `env:"DATA_DIR" default:"${HOME}/data"` // 默认值中的变量引用按快照展开
+//	Secret  string `env:"SESSION_SECRET" defaultFn:"randomHex32"` // 由 RegisterDefaultFunc 注册的函数生成
+//
+// defaultFn 生成的值在进程内只计算一次，之后的重载复用同一个值；通过 Bind 绑定且配置了 Config.GeneratedFile 时还会持久化。
+// 支持字符串、布尔、整数、浮点数、time.Duration 以及实现了 encoding.TextUnmarshaler 的类型。
+// 键未设置且没有默认值时字段保持原值；值无效时返回 ValueError，多个字段无效时返回包含全部错误的 MultiError
+func (s *Snapshot) Decode(v any) error {
+	return s.decode(v, processDefaults, nil)
+}
+
+// decode 解码 v，defaultFn 生成的值保存在 gen 中；keys 非 nil 时记录读取过的键，参见 DependencyGraph
+func (s *Snapshot) decode(v any, gen *generator, keys map[string]bool) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("loadenv: Decode requires a non-nil pointer to a struct, got %T", v)
+	}
+	d := &decoder{s: s, gen: gen, keys: keys}
+	return s.describe(d.decodeStruct(rv.Elem(), ""))
+}
+
+// decoder 一次解码的状态
+type decoder struct {
+	s    *Snapshot
+	gen  *generator
+	keys map[string]bool // 读取过的键，前缀以 * 结尾；为 nil 时不记录
+}
+
+// lookup 读取键并记录
+func (d *decoder) lookup(key string) (string, bool) {
+	if d.keys != nil {
+		d.keys[key] = true
+	}
+	return d.s.Lookup(key)
+}
+
+// group 读取以 prefix 开头的所有键并记录该前缀
+func (d *decoder) group(prefix string) map[string]string {
+	if d.keys != nil {
+		d.keys[prefix+"*"] = true
+	}
+	return d.s.GetGroup(prefix)
+}
+
+// fallback 返回字段的默认值：defaultFn 优先于 default，都没有时 ok 为 false
+func (d *decoder) fallback(f reflect.StructField, key string) (value string, ok bool, err error) {
+	if name, ok := f.Tag.Lookup("defaultFn"); ok {
+		value, err := d.gen.get(key, name)
+		return value, err == nil, err
+	}
+	if def, ok := f.Tag.Lookup("default"); ok {
+		return d.s.Expand(def), true, nil
+	}
+	return "", false, nil
+}
+
+// decodeStruct 解码结构体的每个字段，prefix 为字段键名的前缀
+//
+// 字段出错时继续解码其余字段，返回所有字段的错误
+func (d *decoder) decodeStruct(v reflect.Value, prefix string) error {
+	var errs []error
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name, tagged := f.Tag.Lookup("env")
+		if name == "-" {
+			continue
+		}
+		key := prefix + name
+		fv := v.Field(i)
+		var err error
+		switch {
+		case isStruct(f.Type):
+			p := prefix
+			if tagged {
+				p = key + "_"
+			}
+			err = d.decodeStruct(fv, p)
+		case !tagged:
+			continue
+		case f.Type.Kind() == reflect.Slice && !isScalar(f.Type):
+			err = d.decodeSlice(fv, f, key)
+		case f.Type.Kind() == reflect.Map && !isScalar(f.Type):
+			err = d.decodeMap(fv, key)
+		default:
+			raw, ok := d.lookup(key)
+			if !ok {
+				raw, ok, err = d.fallback(f, key)
+			}
+			if ok {
+				err = d.setScalar(fv, key, raw)
+			}
+		}
+		errs = append(errs, err)
+	}
+	return joinErrors(errs...)
+}
+
+// decodeSlice 解码结构体切片或标量切片
+func (d *decoder) decodeSlice(v reflect.Value, f reflect.StructField, key string) error {
+	elem := v.Type().Elem()
+	if !isStruct(elem) {
+		if raw, ok := d.lookup(key); ok {
+			return d.setList(v, key, raw)
+		}
+	}
+
+	out := reflect.MakeSlice(v.Type(), 0, 0)
+	for i := 0; ; i++ {
+		indexed := key + "_" + strconv.Itoa(i)
+		e := reflect.New(elem).Elem()
+		if isStruct(elem) {
+			if len(d.group(indexed+"_")) == 0 {
+				break
+			}
+			if err := d.decodeStruct(e, indexed+"_"); err != nil {
+				return err
+			}
+		} else {
+			raw, ok := d.lookup(indexed)
+			if !ok {
+				break
+			}
+			if err := d.setScalar(e, indexed, raw); err != nil {
+				return err
+			}
+		}
+		out = reflect.Append(out, e)
+	}
+	if out.Len() > 0 {
+		v.Set(out)
+		return nil
+	}
+	if !isStruct(elem) {
+		raw, ok, err := d.fallback(f, key)
+		if err != nil || !ok {
+			return err
+		}
+		return d.setList(v, key, raw)
+	}
+	return nil
+}
+
+// setList 将逗号分隔的 raw 解析为标量切片存入 v
+func (d *decoder) setList(v reflect.Value, key, raw string) error {
+	parts := strings.Split(raw, ",")
+	if strings.TrimSpace(raw) == "" {
+		parts = nil
+	}
+	out := reflect.MakeSlice(v.Type(), len(parts), len(parts))
+	for i, part := range parts {
+		if err := d.setScalar(out.Index(i), key, strings.TrimSpace(part)); err != nil {
+			return err
+		}
+	}
+	v.Set(out)
+	return nil
+}
+
+// decodeMap 将所有以 key_ 开头的键解码到映射中，映射的键为去掉前缀之后的部分
+func (d *decoder) decodeMap(v reflect.Value, key string) error {
+	t := v.Type()
+	if t.Key().Kind() != reflect.String || isStruct(t.Elem()) {
+		return fmt.Errorf("loadenv: %s: unsupported map type %s", key, t)
+	}
+	group := d.group(key + "_")
+	if len(group) == 0 {
+		return nil
+	}
+	out := reflect.MakeMapWithSize(t, len(group))
+	for suffix, raw := range group {
+		e := reflect.New(t.Elem()).Elem()
+		if err := d.setScalar(e, key+"_"+suffix, raw); err != nil {
+			return err
+		}
+		out.SetMapIndex(reflect.ValueOf(suffix).Convert(t.Key()), e)
+	}
+	v.Set(out)
+	return nil
+}
+
+var (
+	durationType        = reflect.TypeOf(time.Duration(0))
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// isStruct 判断 t 是否按嵌套结构体解码（实现了 encoding.TextUnmarshaler 的结构体如 time.Time 按标量处理）
+func isStruct(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && !reflect.PointerTo(t).Implements(textUnmarshalerType)
+}
+
+// isScalar 判断 t 是否作为单个值解码（[]byte 之类实现了 TextUnmarshaler 的切片类型）
+func isScalar(t reflect.Type) bool {
+	return reflect.PointerTo(t).Implements(textUnmarshalerType)
+}
+
+// setScalar 将 raw 解析后存入 v，布尔值和整数按快照的解析选项解析
+func (d *decoder) setScalar(v reflect.Value, key, raw string) error {
+	var vp *valueParser
+	if d.s != nil {
+		vp = d.s.values
+	}
+	if u, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		if err := u.UnmarshalText([]byte(raw)); err != nil {
+			return &ValueError{Key: key, Value: raw, Want: v.Type().String(), Err: err}
+		}
+		return nil
+	}
+
+	trimmed := strings.TrimSpace(raw)
+	var err error
+	switch {
+	case v.Type() == durationType:
+		var d time.Duration
+		if d, err = time.ParseDuration(trimmed); err == nil {
+			v.SetInt(int64(d))
+		}
+	case v.Kind() == reflect.String:
+		v.SetString(raw)
+	case v.Kind() == reflect.Bool:
+		var b bool
+		if b, err = vp.parseBool(trimmed); err == nil {
+			v.SetBool(b)
+		}
+	case v.CanInt():
+		var n int64
+		if n, err = vp.parseInt(trimmed, 0, v.Type().Bits()); err == nil {
+			v.SetInt(n)
+		}
+	case v.CanUint():
+		var n uint64
+		if n, err = vp.parseUint(trimmed, 0, v.Type().Bits()); err == nil {
+			v.SetUint(n)
+		}
+	case v.CanFloat():
+		var f float64
+		if f, err = strconv.ParseFloat(trimmed, v.Type().Bits()); err == nil {
+			v.SetFloat(f)
+		}
+	default:
+		return fmt.Errorf("loadenv: %s: unsupported field type %s", key, v.Type())
+	}
+	if err != nil {
+		return &ValueError{Key: key, Value: raw, Want: v.Type().String(), Err: err}
+	}
+	return nil
+}
+
+// Validator 由绑定的结构体实现，在解码之后校验业务约束
+type Validator interface {
+	Validate() error
+}
+
+// ValidationError 绑定的结构体未通过校验
+type ValidationError struct {
+	Type string // 结构体类型名
+	Err  error
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("loadenv: %s: validation failed: %v", e.Type, e.Err)
+}
+
+func (e *ValidationError) Unwrap() error { return e.Err }
+
+// validateStruct 依次调用 v 的 Validate 方法（如果实现了 Validator）和 fn（可为 nil）
+func validateStruct(v any, fn func(any) error) error {
+	if vv, ok := v.(Validator); ok {
+		if err := vv.Validate(); err != nil {
+			return &ValidationError{Type: fmt.Sprintf("%T", v), Err: err}
+		}
+	}
+	if fn != nil {
+		if err := fn(v); err != nil {
+			return &ValidationError{Type: fmt.Sprintf("%T", v), Err: err}
+		}
+	}
+	return nil
+}
+
+// checkBindings 以 env 生效后的快照（参见 candidate）解码并校验所有绑定的结构体，任一失败时拒绝该配置，调用方需持有 mu
+func (l *Loader) checkBindings(env map[string]string) error {
+	l.hooksMu.Lock()
+	validators := l.validators
+	l.hooksMu.Unlock()
+	if len(validators) == 0 {
+		return nil
+	}
+	snap := l.candidate(env)
+	var errs []error
+	for _, validate := range validators {
+		errs = append(errs, validate(snap))
+	}
+	return joinErrors(errs...)
+}
+
+// Binding 绑定到配置的结构体，每次重载后重新解码
+type Binding[T any] struct {
+	v        atomic.Pointer[T]
+	onChange func(old, new *T, changes []FieldChange)
+}
+
+// Get 返回最近一次成功解码的值，调用方不应修改它
+func (b *Binding[T]) Get() *T {
+	return b.v.Load()
+}
+
+// Bind 将 l 的配置按 Decode 的规则解码为 T，并在每次重载后重新解码；l 为 nil 时使用默认加载器
+//
+//	cfg, err := loadenv.Bind[AppConfig](l)
+//	...
+//	cfg.Get().Endpoints
+//
+// 解码之后依次执行 *T 的 Validate 方法（如果实现了 Validator）和 Config.ValidateStruct；
+// 绑定之后，解码或校验失败的配置会被拒绝，重载失败并保留原配置，就像准入策略一样。
+// 首次解码或校验失败时返回错误且不建立绑定。默认加载器未初始化时只从进程环境变量解码一次
+func Bind[T any](l *Loader) (*Binding[T], error) {
+	return bind[T](l, nil)
+}
+
+// bind 实现 Bind 和 BindWatch，onChange 可为 nil
+func bind[T any](l *Loader, onChange func(old, new *T, changes []FieldChange)) (*Binding[T], error) {
+	b := &Binding[T]{onChange: onChange}
+	if l == nil {
+		l = std.Load()
+	}
+	var validate func(any) error
+	gen := processDefaults
+	if l != nil {
+		validate, gen = l.cfg.ValidateStruct, l.gen
+	}
+	decode := func(snap *Snapshot, keys map[string]bool) (*T, error) {
+		v := new(T)
+		if err := snap.decode(v, gen, keys); err != nil {
+			return nil, err
+		}
+		if err := validateStruct(v, validate); err != nil {
+			return nil, err
+		}
+		return v, nil
+	}
+
+	snap := current()
+	if l != nil {
+		snap = l.Snapshot()
+	}
+	v, err := decode(snap, nil)
+	if err != nil {
+		return nil, err
+	}
+	b.v.Store(v)
+	if l == nil {
+		return b, nil
+	}
+
+	name, site := registration()
+	dep := l.addDependent(name+"["+reflect.TypeFor[T]().String()+"]", site)
+	l.hooksMu.Lock()
+	l.validators = append(l.validators, func(snap *Snapshot) error {
+		_, err := decode(snap, nil)
+		return err
+	})
+	l.hooksMu.Unlock()
+	l.onSnapshot(func(snap *Snapshot) {
+		keys := make(map[string]bool)
+		v, err := decode(snap, keys)
+		l.setKeys(dep, keys)
+		if err != nil {
+			l.logger.Printf("Binding not updated: %v", err)
+			return
+		}
+		old := b.v.Swap(v)
+		if b.onChange != nil {
+			if changes := diffFields(reflect.ValueOf(old).Elem(), reflect.ValueOf(v).Elem(), ""); len(changes) > 0 {
+				b.onChange(old, v, changes)
+			}
+		}
+	})
+	return b, nil
+}
+
+// BindWatch 与 Bind 相同，并在重载使绑定的值发生变化时调用 fn，报告变化的字段及其新旧值
+//
+// fn 在重载协程中同步执行，首次绑定时不调用。old 和 new 为解码后的完整值，调用方不应修改
+func BindWatch[T any](l *Loader, fn func(old, new *T, changes []FieldChange)) (*Binding[T], error) {
+	return bind(l, fn)
+}
+
+// FieldChange 绑定的结构体中一个字段的变化
+type FieldChange struct {
+	Field string // 字段路径，如 DB.Port、Endpoints[1].URL、Headers[X_TRACE]
+	Old   any    // 旧值，字段不存在（如切片变长）时为 nil
+	New   any    // 新值，字段不再存在时为 nil
+}
+
+// diffFields 比较两个值，返回变化的字段；嵌套结构体、结构体切片和映射逐项比较，其他值整体比较
+func diffFields(old, new reflect.Value, path string) []FieldChange {
+	t := new.Type()
+	switch {
+	case isStruct(t):
+		var changes []FieldChange
+		for i := 0; i < t.NumField(); i++ {
+			if f := t.Field(i); f.IsExported() {
+				changes = append(changes, diffFields(old.Field(i), new.Field(i), join(path, f.Name))...)
+			}
+		}
+		return changes
+	case t.Kind() == reflect.Slice && isStruct(t.Elem()):
+		var changes []FieldChange
+		for i := 0; i < max(old.Len(), new.Len()); i++ {
+			p := fmt.Sprintf("%s[%d]", path, i)
+			switch {
+			case i >= old.Len():
+				changes = append(changes, FieldChange{Field: p, New: new.Index(i).Interface()})
+			case i >= new.Len():
+				changes = append(changes, FieldChange{Field: p, Old: old.Index(i).Interface()})
+			default:
+				changes = append(changes, diffFields(old.Index(i), new.Index(i), p)...)
+			}
+		}
+		return changes
+	case t.Kind() == reflect.Map && !isScalar(t):
+		var changes []FieldChange
+		keys := make(map[string]reflect.Value)
+		for _, k := range append(old.MapKeys(), new.MapKeys()...) {
+			keys[k.String()] = k
+		}
+		names := make([]string, 0, len(keys))
+		for name := range keys {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			o, n := old.MapIndex(keys[name]), new.MapIndex(keys[name])
+			c := FieldChange{Field: fmt.Sprintf("%s[%s]", path, name)}
+			if o.IsValid() {
+				c.Old = o.Interface()
+			}
+			if n.IsValid() {
+				c.New = n.Interface()
+			}
+			if !reflect.DeepEqual(c.Old, c.New) {
+				changes = append(changes, c)
+			}
+		}
+		return changes
+	}
+	if reflect.DeepEqual(old.Interface(), new.Interface()) {
+		return nil
+	}
+	return []FieldChange{{Field: path, Old: old.Interface(), New: new.Interface()}}
+}
+
+// join 拼接字段路径
+func join(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
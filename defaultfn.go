@@ -0,0 +1,111 @@
+package loadenv
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"strconv"
+	"sync"
+)
+
+// DefaultFunc 生成字段默认值的函数，通过 defaultFn 标签引用，参见 Snapshot.Decode
+type DefaultFunc func() (string, error)
+
+var (
+	defaultFuncsMu sync.RWMutex
+	defaultFuncs   = map[string]DefaultFunc{
+		"randomHex32": randomHex(32),
+		"randomHex16": randomHex(16),
+		"hostname":    os.Hostname,
+		"freePort":    freePort,
+	}
+)
+
+// RegisterDefaultFunc 注册一个可在 defaultFn 标签中引用的默认值生成函数，同名时覆盖
+//
+// 内置：randomHex16、randomHex32（16/32 字节随机数的十六进制）、hostname、freePort（当前空闲的 TCP 端口）
+func RegisterDefaultFunc(name string, fn DefaultFunc) {
+	defaultFuncsMu.Lock()
+	defaultFuncs[name] = fn
+	defaultFuncsMu.Unlock()
+}
+
+// randomHex 返回生成 n 字节随机数十六进制表示的函数
+func randomHex(n int) DefaultFunc {
+	return func() (string, error) {
+		b := make([]byte, n)
+		if _, err := rand.Read(b); err != nil {
+			return "", err
+		}
+		return hex.EncodeToString(b), nil
+	}
+}
+
+// freePort 返回一个当前空闲的 TCP 端口
+func freePort() (string, error) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return "", err
+	}
+	defer ln.Close()
+	return strconv.Itoa(ln.Addr().(*net.TCPAddr).Port), nil
+}
+
+// generator 保存 defaultFn 生成的值，每个键只生成一次；path 非空时持久化到该文件
+type generator struct {
+	mu     sync.Mutex
+	values map[string]string
+	path   string
+}
+
+// processDefaults 未绑定到加载器时使用的进程级 generator，不持久化
+var processDefaults = &generator{values: make(map[string]string)}
+
+// newGenerator 创建 generator，path 非空且文件存在时读取其中已生成的值
+func newGenerator(fsys FS, path string) (*generator, error) {
+	g := &generator{values: make(map[string]string), path: path}
+	if path == "" {
+		return g, nil
+	}
+	data, err := fs.ReadFile(fsys, path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return g, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if g.values, err = ParseBytes(data); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return g, nil
+}
+
+// get 返回键的生成值，首次调用时以名为 name 的函数生成
+func (g *generator) get(key, name string) (string, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if v, ok := g.values[key]; ok {
+		return v, nil
+	}
+	defaultFuncsMu.RLock()
+	fn, ok := defaultFuncs[name]
+	defaultFuncsMu.RUnlock()
+	if !ok {
+		return "", fmt.Errorf("loadenv: %s: unknown defaultFn %q", key, name)
+	}
+	v, err := fn()
+	if err != nil {
+		return "", fmt.Errorf("loadenv: %s: defaultFn %s: %w", key, name, err)
+	}
+	g.values[key] = v
+	if g.path != "" {
+		if err := os.WriteFile(g.path, Marshal(g.values), 0o600); err != nil {
+			return "", err
+		}
+	}
+	return v, nil
+}
@@ -0,0 +1,99 @@
+package loadenv
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// MissingKeysError 模式文件要求的键没有值
+type MissingKeysError struct {
+	Schema string   // 模式文件路径
+	Keys   []string // 缺失的键（已排序）
+
+	Descriptions map[string]string // 缺失的键的说明（来自注解，参见 KeyMeta），可为 nil
+}
+
+func (e *MissingKeysError) Error() string {
+	keys := make([]string, len(e.Keys))
+	for i, key := range e.Keys {
+		keys[i] = key
+		if desc := e.Descriptions[key]; desc != "" {
+			keys[i] += " (" + desc + ")"
+		}
+	}
+	return fmt.Sprintf("loadenv: missing required variables (from %s): %s", e.Schema, strings.Join(keys, ", "))
+}
+
+// sidecar 返回与环境文件配套的文件路径，相对路径相对于环境文件所在目录
+func (l *Loader) sidecar(name string) string {
+	if filepath.IsAbs(name) {
+		return name
+	}
+	return filepath.Join(filepath.Dir(l.absPath), name)
+}
+
+// readDefaults 读取 DefaultsFile 中的默认值，未配置或文件不存在时返回 nil
+func (l *Loader) readDefaults() (map[string]string, string, error) {
+	if l.cfg.DefaultsFile == "" {
+		return nil, "", nil
+	}
+	path := l.sidecar(l.cfg.DefaultsFile)
+	data, err := fs.ReadFile(l.cfg.FS, path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, path, nil
+	}
+	if err != nil {
+		return nil, path, err
+	}
+	if data, err = decode(data, l.cfg.Encoding); err != nil {
+		return nil, path, err
+	}
+	defaults, err := parse(data, os.LookupEnv, l.cfg.Parse)
+	if err != nil {
+		return nil, path, fmt.Errorf("%s: %w", path, err)
+	}
+	if err := l.checkPlaintext(path, defaults); err != nil {
+		return nil, path, err
+	}
+	return defaults, path, nil
+}
+
+// checkSchema 确认 SchemaFile 中列出的每个键都有非空值（文件中的值或非 Isolated 时的进程环境变量）
+func (l *Loader) checkSchema(env map[string]string) error {
+	if l.cfg.SchemaFile == "" {
+		return nil
+	}
+	path := l.sidecar(l.cfg.SchemaFile)
+	data, err := fs.ReadFile(l.cfg.FS, path)
+	if err != nil {
+		return err
+	}
+	if data, err = decode(data, l.cfg.Encoding); err != nil {
+		return err
+	}
+	schema, err := ParseBytes(data)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	var missing []string
+	for key := range schema {
+		v, ok := env[key]
+		if !ok && !l.cfg.Isolated {
+			v, ok = os.LookupEnv(key)
+		}
+		if !ok || v == "" {
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return &MissingKeysError{Schema: path, Keys: missing}
+	}
+	return nil
+}
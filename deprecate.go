@@ -0,0 +1,89 @@
+package loadenv
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Deprecation 声明一个计划移除的键
+//
+// 移除日期之前，读取仍有值的该键时记录一次警告；移除日期之后，
+// 环境文件或来源中仍出现该键时加载失败（WarnExpiredDeprecations 时仍只记录警告）
+type Deprecation struct {
+	Key         string    // 弃用的键
+	RemoveAfter time.Time // 移除日期
+	Replacement string    // 替代的键，可为空，只用于提示
+}
+
+func (d Deprecation) String() string {
+	s := fmt.Sprintf("%s is deprecated and scheduled for removal after %s", d.Key, d.RemoveAfter.Format(time.DateOnly))
+	if d.Replacement != "" {
+		s += ", use " + d.Replacement + " instead"
+	}
+	return s
+}
+
+// DeprecatedError 配置中仍包含已过移除日期的键
+type DeprecatedError struct {
+	Deprecations []Deprecation // 按键排序
+}
+
+func (e *DeprecatedError) Error() string {
+	msgs := make([]string, len(e.Deprecations))
+	for i, d := range e.Deprecations {
+		msgs[i] = d.Key + " (removed after " + d.RemoveAfter.Format(time.DateOnly) + ")"
+		if d.Replacement != "" {
+			msgs[i] += ", use " + d.Replacement
+		}
+	}
+	return "loadenv: config still sets removed keys: " + strings.Join(msgs, "; ")
+}
+
+// checkDeprecations 检查 env 中是否仍有已过移除日期的键
+func (l *Loader) checkDeprecations(env map[string]string) error {
+	if len(l.cfg.Deprecations) == 0 {
+		return nil
+	}
+	now := l.cfg.Clock.Now()
+	var expired []Deprecation
+	for _, d := range l.cfg.Deprecations {
+		if _, ok := env[d.Key]; ok && now.After(d.RemoveAfter) {
+			expired = append(expired, d)
+		}
+	}
+	if len(expired) == 0 {
+		return nil
+	}
+	if l.cfg.WarnExpiredDeprecations {
+		for _, d := range expired {
+			l.logger.Printf("Warning: %s is past its removal date %s but still set", d.Key, d.RemoveAfter.Format(time.DateOnly))
+		}
+		return nil
+	}
+	sort.Slice(expired, func(i, j int) bool { return expired[i].Key < expired[j].Key })
+	return &DeprecatedError{Deprecations: expired}
+}
+
+// accessHook 返回快照的读取回调：读取有值的弃用键时每个键记录一次警告，然后调用 Config.OnAccess
+func (l *Loader) accessHook() func(key string, found bool) {
+	if len(l.cfg.Deprecations) == 0 {
+		return l.cfg.OnAccess
+	}
+	deprecated := make(map[string]Deprecation, len(l.cfg.Deprecations))
+	for _, d := range l.cfg.Deprecations {
+		deprecated[d.Key] = d
+	}
+	onAccess := l.cfg.OnAccess
+	return func(key string, found bool) {
+		if d, ok := deprecated[key]; ok && found {
+			if _, warned := l.warned.LoadOrStore(key, true); !warned {
+				l.logger.Printf("Warning: %s", d)
+			}
+		}
+		if onAccess != nil {
+			onAccess(key, found)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package loadenv
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Component 依赖配置的一个组件：通过 Bind 系列函数绑定的值、派生值或分组
+type Component struct {
+	Name string   `json:"name"`           // 注册方式，如 Bind[main.Config]、BindInt64Var、RegisterDerived(DSN)、Group(limits)
+	Site string   `json:"site,omitempty"` // 注册位置（文件:行号），通过 Config 声明的分组为空
+	Keys []string `json:"keys"`           // 最近一次读取过的键（已排序），以 * 结尾的表示该前缀下的所有键
+}
+
+// DependsOn 判断组件是否依赖键 key
+func (c Component) DependsOn(key string) bool {
+	for _, k := range c.Keys {
+		if k == key || strings.HasSuffix(k, "*") && strings.HasPrefix(key, k[:len(k)-1]) {
+			return true
+		}
+	}
+	return false
+}
+
+// DependencyGraph 组件与其依赖的键之间的关系，用于在修改生产环境的某个变量之前评估影响范围
+//
+// 依赖在运行时记录：绑定的结构体和派生值在每次重新读取配置后更新，
+// 因此切片、映射等按前缀读取的字段反映的是当前配置中实际存在的键。
+// 可以直接用 encoding/json 序列化，或通过 DOT 输出 Graphviz 格式
+type DependencyGraph struct {
+	Components []Component `json:"components"` // 按注册顺序排列：绑定、派生值、分组
+}
+
+// Dependents 返回依赖键 key 的组件
+func (g *DependencyGraph) Dependents(key string) []Component {
+	var out []Component
+	for _, c := range g.Components {
+		if c.DependsOn(key) {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// DOT 以 Graphviz DOT 格式输出依赖图，边从键指向依赖它的组件
+func (g *DependencyGraph) DOT() string {
+	var b strings.Builder
+	b.WriteString("digraph loadenv {\n\trankdir=LR;\n\tnode [shape=box];\n")
+	keys := map[string]bool{}
+	for _, c := range g.Components {
+		for _, k := range c.Keys {
+			keys[k] = true
+		}
+	}
+	for _, k := range sortedSet(keys) {
+		fmt.Fprintf(&b, "\t%s;\n", strconv.Quote(k))
+	}
+	for i, c := range g.Components {
+		label := c.Name
+		if c.Site != "" {
+			label += "\n" + c.Site
+		}
+		fmt.Fprintf(&b, "\tc%d [shape=ellipse, label=%s];\n", i, strconv.Quote(label))
+		for _, k := range c.Keys {
+			fmt.Fprintf(&b, "\t%s -> c%d;\n", strconv.Quote(k), i)
+		}
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// DependencyGraph 返回当前注册的组件及其依赖的键
+func (l *Loader) DependencyGraph() *DependencyGraph {
+	g := &DependencyGraph{}
+	l.hooksMu.Lock()
+	for _, d := range l.dependents {
+		g.Components = append(g.Components, Component{Name: d.name, Site: d.site, Keys: d.keys})
+	}
+	l.hooksMu.Unlock()
+
+	l.mu.RLock()
+	for _, d := range l.derived {
+		g.Components = append(g.Components, Component{Name: "RegisterDerived(" + d.key + ")", Site: d.site, Keys: sortedSet(d.deps)})
+	}
+	l.mu.RUnlock()
+
+	for _, grp := range l.cfg.Groups {
+		keys := append([]string(nil), grp.Keys...)
+		if grp.Prefix != "" {
+			keys = append(keys, grp.Prefix+"*")
+		}
+		sort.Strings(keys)
+		g.Components = append(g.Components, Component{Name: "Group(" + grp.Name + ")", Keys: keys})
+	}
+	return g
+}
+
+// GetDependencyGraph 返回默认加载器的依赖图，未初始化时为空
+func GetDependencyGraph() *DependencyGraph {
+	if l := std.Load(); l != nil {
+		return l.DependencyGraph()
+	}
+	return &DependencyGraph{}
+}
+
+// dependent 一个通过 Bind 系列函数注册的组件，keys 由 hooksMu 保护
+type dependent struct {
+	name, site string
+	keys       []string
+}
+
+// addDependent 记录一个组件，返回的 dependent 用于之后更新依赖的键
+func (l *Loader) addDependent(name, site string, keys ...string) *dependent {
+	d := &dependent{name: name, site: site, keys: keys}
+	l.hooksMu.Lock()
+	l.dependents = append(l.dependents, d)
+	l.hooksMu.Unlock()
+	return d
+}
+
+// setKeys 更新组件依赖的键
+func (l *Loader) setKeys(d *dependent, keys map[string]bool) {
+	sorted := sortedSet(keys)
+	l.hooksMu.Lock()
+	d.keys = sorted
+	l.hooksMu.Unlock()
+}
+
+// sortedSet 返回集合中的键（已排序）
+func sortedSet(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// pkgPrefix 本包函数名的前缀
+var pkgPrefix = reflect.TypeFor[Loader]().PkgPath() + "."
+
+// registration 返回调用方通过哪个导出函数注册（如 BindInt64Var）以及注册位置（包外第一个调用者的文件:行号）
+func registration() (name, site string) {
+	pcs := make([]uintptr, 32)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(2, pcs)])
+	for {
+		f, more := frames.Next()
+		if !strings.HasPrefix(f.Function, pkgPrefix) {
+			if f.Function != "" {
+				site = f.File + ":" + strconv.Itoa(f.Line)
+			}
+			return name, site
+		}
+		fn := strings.TrimPrefix(f.Function, pkgPrefix)
+		if i := strings.IndexByte(fn, '['); i >= 0 {
+			fn = fn[:i]
+		}
+		if i := strings.LastIndexByte(fn, '.'); i >= 0 {
+			fn = fn[i+1:]
+		}
+		if fn != "" && fn[0] >= 'A' && fn[0] <= 'Z' {
+			name = fn
+		}
+		if !more {
+			return name, site
+		}
+	}
+}
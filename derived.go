@@ -0,0 +1,173 @@
+package loadenv
+
+import "slices"
+
+// DeriveFunc 根据其他键计算派生值
+type DeriveFunc func(env ReadOnlyEnv) string
+
+// derivedValue 一个已注册的派生值及其依赖
+type derivedValue struct {
+	key   string
+	fn    DeriveFunc
+	deps  map[string]bool // 上次计算时读取过的键
+	site  string          // 注册位置，参见 DependencyGraph
+	value string
+}
+
+// depTracker 记录派生函数读取了哪些键
+type depTracker struct {
+	env  *Snapshot
+	deps map[string]bool
+}
+
+func (t *depTracker) Get(key string) string {
+	v, _ := t.Lookup(key)
+	return v
+}
+
+func (t *depTracker) Lookup(key string) (string, bool) {
+	t.deps[key] = true
+	return t.env.lookup(key)
+}
+
+// RegisterDerived 注册一个派生值，例如
+//
+//	l.RegisterDerived("DSN", func(env loadenv.ReadOnlyEnv) string {
+//		return fmt.Sprintf("postgres://%s@%s/%s", env.Get("DB_USER"), env.Get("DB_HOST"), env.Get("DB_NAME"))
+//	})
+//
+// 派生值立即计算一次，之后每次重载时，仅当它上次读取过的键发生变化才重新计算。
+// 派生值通过快照读取（来源为 derived），不写入进程环境变量；
+// 派生值可以依赖先注册的派生值，与文件中的键同名时覆盖文件中的值。重复注册同一个键会替换之前的函数。
+// 注册后的快照与重载一样经过钩子和订阅通知；与 Reload 一样，不能在钩子中调用。
+// 配置已冻结（参见 Freeze）时不注册并返回 ErrFrozen
+func (l *Loader) RegisterDerived(key string, fn DeriveFunc) error {
+	_, site := registration()
+	// Lazy 加载器加载失败时仍然注册，下一次加载时计算
+	l.EnsureLoaded()
+	l.reloadMu.Lock()
+	defer l.reloadMu.Unlock()
+	if l.frozen.Load() {
+		return ErrFrozen
+	}
+	start := l.cfg.Clock.Now()
+	prev, snap := l.registerDerived(key, fn, site)
+	if snap == nil {
+		return nil
+	}
+	end := l.cfg.Clock.Now()
+	l.finish(prev, snap, ReloadMeta{
+		Checksum:   snap.Checksum(),
+		DetectedAt: start,
+		AppliedAt:  end,
+		Latency:    end.Sub(start),
+	})
+	return nil
+}
+
+// registerDerived 注册派生值并在当前快照的副本上计算，返回之前和新的快照；没有当前快照或计算失败时 snap 为 nil。
+// 调用方需持有 reloadMu
+func (l *Loader) registerDerived(key string, fn DeriveFunc, site string) (prev, snap *Snapshot) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	d := &derivedValue{key: key, fn: fn, site: site}
+	replaced := false
+	for i, old := range l.derived {
+		if old.key == key {
+			l.derived[i] = d
+			replaced = true
+		}
+	}
+	if !replaced {
+		l.derived = append(l.derived, d)
+	}
+
+	// 在当前快照的副本上重新计算，新注册的值必定计算，其余只计算受影响的
+	prev = l.snapshot.Load()
+	if prev == nil {
+		return nil, nil
+	}
+	snap = &Snapshot{
+		env:      prev.Map(),
+		origins:  make(map[string]string, len(prev.origins)+1),
+		onAccess: prev.onAccess,
+		isolated: prev.isolated,
+		loadedAt: prev.loadedAt,
+		meta:     prev.meta,
+		values:   prev.values,
+	}
+	for k, v := range prev.origins {
+		snap.origins[k] = v
+	}
+	l.defs = make(map[string][]Definition, len(prev.defs))
+	for k, defs := range prev.defs {
+		// 重新计算的派生值会再次记录
+		l.defs[k] = slices.DeleteFunc(slices.Clone(defs), func(d Definition) bool { return d.Source == SourceDerived })
+	}
+	l.derive(prev, snap)
+	l.preferOS(snap)
+	snap.defs = l.defs
+	snap.checksum = HashEnv(snap.env)
+	if err := l.seal(snap); err != nil {
+		l.logger.Printf("Failed to seal secrets: %v", err)
+		return prev, nil
+	}
+	l.lockSecrets(snap)
+	l.store(snap)
+	return prev, snap
+}
+
+// derive 在 snap 上计算派生值，只重新计算依赖相对 prev 发生变化的项，调用方需持有 mu
+func (l *Loader) derive(prev, snap *Snapshot) {
+	l.computeDerived(prev, snap, true)
+}
+
+// computeDerived 实现 derive；commit 为 false 时不更新派生值的缓存和定义记录，用于预览尚未生效的配置
+func (l *Loader) computeDerived(prev, snap *Snapshot, commit bool) {
+	if len(l.derived) == 0 {
+		return
+	}
+	// 只比较派生之前的值：prev 中包含派生值，snap 中还没有
+	derived := make(map[string]bool, len(l.derived))
+	for _, d := range l.derived {
+		derived[d.key] = true
+	}
+	changed := make(map[string]bool)
+	for _, c := range diffEnv(prev.Map(), snap.env) {
+		if !derived[c.Key] {
+			changed[c.Key] = true
+		}
+	}
+	for _, d := range l.derived {
+		value, deps := d.value, d.deps
+		stale := deps == nil
+		for key := range deps {
+			if changed[key] {
+				stale = true
+				break
+			}
+		}
+		if stale {
+			t := &depTracker{env: snap, deps: make(map[string]bool)}
+			value, deps = d.fn(t), t.deps
+			if value != d.value || d.deps == nil {
+				changed[d.key] = true
+			}
+		}
+		snap.env[d.key] = value
+		snap.origins[d.key] = SourceDerived
+		if commit {
+			d.value, d.deps = value, deps
+			l.define(d.key, SourceDerived, value)
+		}
+	}
+}
+
+// RegisterDerived 在默认加载器上注册派生值，参见 Loader.RegisterDerived；未初始化时不做任何事
+func RegisterDerived(key string, fn DeriveFunc) error {
+	if l := std.Load(); l != nil {
+		return l.RegisterDerived(key, fn)
+	}
+	return nil
+}
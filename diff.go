@@ -0,0 +1,61 @@
+package loadenv
+
+import (
+	"slices"
+	"strings"
+)
+
+// ChangeType 变更类型
+type ChangeType int
+
+const (
+	Added    ChangeType = iota // 新增
+	Modified                   // 修改
+	Removed                    // 删除
+)
+
+// String 返回变更类型的名称
+func (t ChangeType) String() string {
+	switch t {
+	case Added:
+		return "added"
+	case Modified:
+		return "modified"
+	case Removed:
+		return "removed"
+	}
+	return "unknown"
+}
+
+// Change 单个环境变量的变更
+type Change struct {
+	Key  string
+	Type ChangeType
+	Old  string // 旧值（新增时为空）
+	New  string // 新值（删除时为空）
+}
+
+// diffEnv 比较新旧两份变量，返回按键名排序的变更列表
+func diffEnv(oldEnv, newEnv map[string]string) []Change {
+	var changes []Change
+	for key, newValue := range newEnv {
+		oldValue, exists := oldEnv[key]
+		if !exists {
+			changes = append(changes, Change{Key: key, Type: Added, New: newValue})
+		} else if oldValue != newValue {
+			changes = append(changes, Change{Key: key, Type: Modified, Old: oldValue, New: newValue})
+		}
+	}
+	for key, oldValue := range oldEnv {
+		if _, exists := newEnv[key]; !exists {
+			changes = append(changes, Change{Key: key, Type: Removed, Old: oldValue})
+		}
+	}
+	slices.SortFunc(changes, func(a, b Change) int { return strings.Compare(a.Key, b.Key) })
+	return changes
+}
+
+// Diff 比较两份键值对，返回按键名排序的变更列表
+func Diff(oldEnv, newEnv map[string]string) []Change {
+	return diffEnv(oldEnv, newEnv)
+}
@@ -0,0 +1,72 @@
+package loadenv
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// runDocker 按 docker run --env-file 的规则解析，参见 ParseOptions.DockerEnvFile
+//
+// 与 Docker CLI 的 parseKeyValueFile 一致：去掉行首空白后，空行和 # 开头的行被忽略；
+// 其余每行在第一个 = 处切分，值原样保留（引号、# 和行尾空白都是值的一部分，没有转义和变量引用）；
+// 变量名不能为空或包含空白（因此 export 前缀是错误）；
+// 只有变量名的行取 lookup 中的值，未设置时忽略该行；文件必须是合法的 UTF-8
+func (p *parser) runDocker() error {
+	for !p.eof() {
+		line, start := p.line, p.pos
+		end := strings.IndexByte(p.src[p.pos:], '\n')
+		if end < 0 {
+			end = len(p.src) - p.pos
+		}
+		raw := p.src[p.pos : p.pos+end]
+		p.pos += end
+		if !p.eof() {
+			p.next()
+		}
+		// bufio.ScanLines 同样去掉最后一行末尾的 \r
+		raw = strings.TrimSuffix(raw, "\r")
+
+		if !utf8.ValidString(raw) {
+			return p.errorf(line, "invalid UTF-8")
+		}
+		text := strings.TrimLeftFunc(raw, unicode.IsSpace)
+		if text == "" {
+			p.note = nil
+			continue
+		}
+		if strings.HasPrefix(text, "#") {
+			if p.notes != nil {
+				p.note = append(p.note, strings.TrimSpace(text[1:]))
+			}
+			continue
+		}
+
+		key, value, hasValue := strings.Cut(text, "=")
+		if strings.ContainsAny(key, " \t") {
+			return p.errorf(line, "variable %q contains whitespace", key)
+		}
+		if key == "" {
+			return p.errorf(line, "no variable name in %q", text)
+		}
+		if !hasValue {
+			v, ok := "", false
+			if p.lookup != nil {
+				v, ok = p.lookup(key)
+			}
+			if !ok {
+				p.note = nil
+				continue
+			}
+			value = v
+		}
+		p.env[key] = value
+		if p.spans != nil {
+			p.spans[key] = append(p.spans[key], [2]int{start, p.pos})
+		}
+		if p.notes != nil {
+			p.notes[key], p.note = p.note, nil
+		}
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package loadenv
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DopplerSource 从 Doppler 读取某个项目配置（config）下的全部密钥
+//
+// 使用服务令牌时 Project 和 Config 可以留空（令牌已绑定具体配置）。
+// 变更通过轮询（PollInterval）发现，也可以把 WebhookHandler 挂到 HTTP 服务上接收 Doppler webhook 以立即生效
+type DopplerSource struct {
+	Token         string        // 服务令牌或个人令牌
+	Project       string        // 项目名称
+	Config        string        // 配置名称，例如 prd
+	PollInterval  time.Duration // 轮询间隔，默认 1 分钟
+	WebhookSecret string        // webhook 签名密钥，为空时不校验
+	BaseURL       string        // API 地址，默认 https://api.doppler.com
+	Client        *http.Client  // 发送请求的客户端（代理、CA、超时等），默认 http.DefaultClient
+
+	poller
+}
+
+var (
+	_ WatchableSource = (*DopplerSource)(nil)
+	_ WritableSource  = (*DopplerSource)(nil)
+)
+
+// Name 返回来源名称
+func (s *DopplerSource) Name() string { return "doppler:" + s.Project + "/" + s.Config }
+
+func (s *DopplerSource) baseURL() string {
+	if s.BaseURL != "" {
+		return s.BaseURL
+	}
+	return "https://api.doppler.com"
+}
+
+// Load 下载全部密钥
+func (s *DopplerSource) Load(ctx context.Context) (map[string]string, error) {
+	base := s.baseURL()
+	q := url.Values{"format": {"json"}}
+	if s.Project != "" {
+		q.Set("project", s.Project)
+	}
+	if s.Config != "" {
+		q.Set("config", s.Config)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, base+"/v3/configs/config/secrets/download?"+q.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+s.Token)
+	req.Header.Set("Accept", "application/json")
+
+	var env map[string]string
+	if err := doJSON(s.Client, req, &env); err != nil {
+		return nil, err
+	}
+	return env, nil
+}
+
+// Store 新增或更新密钥
+func (s *DopplerSource) Store(ctx context.Context, env map[string]string) error {
+	payload, err := json.Marshal(map[string]any{
+		"project": s.Project,
+		"config":  s.Config,
+		"secrets": env,
+	})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.baseURL()+"/v3/configs/config/secrets", bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+s.Token)
+	req.Header.Set("Content-Type", "application/json")
+	return doJSON(s.Client, req, &json.RawMessage{})
+}
+
+// Watch 轮询或在收到 webhook 时检查变更
+func (s *DopplerSource) Watch(ctx context.Context, notify func()) error {
+	return s.watch(ctx, s.Name(), s.PollInterval, s.Load, notify)
+}
+
+// WebhookHandler 返回接收 Doppler webhook 的处理器，校验 X-Doppler-Signature 头
+func (s *DopplerSource) WebhookHandler() http.Handler {
+	var verify func(*http.Request, []byte) bool
+	if s.WebhookSecret != "" {
+		verify = func(r *http.Request, body []byte) bool {
+			got := strings.TrimPrefix(r.Header.Get("X-Doppler-Signature"), "sha256=")
+			return hmac.Equal([]byte(got), []byte(hmacSHA256Hex(s.WebhookSecret, body)))
+		}
+	}
+	return s.webhookHandler(verify)
+}
+
+// doJSON 用 client（nil 表示 http.DefaultClient）发送请求并把 JSON 响应解码到 v，非 2xx 状态码返回错误
+func doJSON(client *http.Client, req *http.Request, v any) error {
+	resp, err := httpClient(client).Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s %s: %s: %s", req.Method, req.URL.Path, resp.Status, strings.TrimSpace(string(body)))
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
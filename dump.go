@@ -0,0 +1,44 @@
+package loadenv
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// DumpOptions DumpTo 的输出选项
+type DumpOptions struct {
+	Redact bool // 隐藏敏感键（参见 IsSecretKey）的值
+	Sort   bool // 按键名排序；否则按来源分组，组内按键名排序
+}
+
+// DumpTo 以表格形式输出快照中的有效配置及每个键的来源
+func (s *Snapshot) DumpTo(w io.Writer, opts DumpOptions) error {
+	keys := s.Keys()
+	if !opts.Sort {
+		sort.SliceStable(keys, func(i, j int) bool { return s.Source(keys[i]) < s.Source(keys[j]) })
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "KEY\tVALUE\tSOURCE")
+	for _, k := range keys {
+		v := s.Get(k)
+		if opts.Redact && IsSecretKey(k) {
+			v = Redacted
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", k, strings.ReplaceAll(v, "\n", `\n`), s.Source(k))
+	}
+	return tw.Flush()
+}
+
+// DumpTo 输出当前配置，参见 Snapshot.DumpTo
+func (l *Loader) DumpTo(w io.Writer, opts DumpOptions) error {
+	return l.Snapshot().DumpTo(w, opts)
+}
+
+// DumpTo 输出默认加载器的当前配置，参见 Snapshot.DumpTo
+func DumpTo(w io.Writer, opts DumpOptions) error {
+	return current().DumpTo(w, opts)
+}
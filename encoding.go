@@ -0,0 +1,41 @@
+package loadenv
+
+import (
+	"bytes"
+	"fmt"
+
+	"golang.org/x/text/encoding/htmlindex"
+	"golang.org/x/text/encoding/unicode"
+	textransform "golang.org/x/text/transform"
+)
+
+// decode 将文件内容转换为 UTF-8
+//
+// 带 BOM 的 UTF-8/UTF-16 文件总是按 BOM 解码；否则按 encoding 指定的编码
+// （WHATWG 名称，例如 windows-1252、gbk、gb18030、shift_jis）解码，为空表示 UTF-8
+func decode(data []byte, encoding string) ([]byte, error) {
+	switch {
+	case bytes.HasPrefix(data, []byte{0xEF, 0xBB, 0xBF}):
+		return data[3:], nil
+	case bytes.HasPrefix(data, []byte{0xFF, 0xFE}), bytes.HasPrefix(data, []byte{0xFE, 0xFF}):
+		dec := unicode.UTF16(unicode.BigEndian, unicode.ExpectBOM).NewDecoder()
+		out, _, err := textransform.Bytes(dec, data)
+		return out, err
+	}
+
+	if encoding == "" {
+		return data, nil
+	}
+	enc, err := htmlindex.Get(encoding)
+	if err != nil {
+		return nil, fmt.Errorf("loadenv: unknown encoding %q", encoding)
+	}
+	if name, _ := htmlindex.Name(enc); name == "utf-8" {
+		return data, nil
+	}
+	out, _, err := textransform.Bytes(enc.NewDecoder(), data)
+	if err != nil {
+		return nil, fmt.Errorf("loadenv: decode %s: %w", encoding, err)
+	}
+	return out, nil
+}
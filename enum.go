@@ -0,0 +1,42 @@
+package loadenv
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// GetEnum 返回键的值，要求为 allowed 之一，例如 GetEnum("LOG_LEVEL", []string{"debug", "info"})
+func (s *Snapshot) GetEnum(key string, allowed []string) (string, error) {
+	return GetEnumAs(s, key, allowed)
+}
+
+// GetEnum 参见 Snapshot.GetEnum
+func (l *Loader) GetEnum(key string, allowed []string) (string, error) {
+	return l.Snapshot().GetEnum(key, allowed)
+}
+
+// GetEnum 从默认加载器读取枚举值，参见 Snapshot.GetEnum
+func GetEnum(key string, allowed []string) (string, error) {
+	return current().GetEnum(key, allowed)
+}
+
+// GetEnumAs 从 env 读取枚举值并转换为自定义字符串类型
+//
+//	type Level string
+//	const (Debug Level = "debug"; Info Level = "info")
+//	lvl, err := loadenv.GetEnumAs(loadenv.GetSnapshot(), "LOG_LEVEL", []Level{Debug, Info})
+func GetEnumAs[T ~string](env ReadOnlyEnv, key string, allowed []T) (T, error) {
+	v, ok := env.Lookup(key)
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrNotSet, key)
+	}
+	if slices.Contains(allowed, T(v)) {
+		return T(v), nil
+	}
+	names := make([]string, len(allowed))
+	for i, a := range allowed {
+		names[i] = string(a)
+	}
+	return "", &ValueError{Key: key, Value: v, Want: "value, allowed: " + strings.Join(names, ", ")}
+}
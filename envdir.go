@@ -0,0 +1,94 @@
+package loadenv
+
+import (
+	"context"
+	"io/fs"
+	"path/filepath"
+	"strings"
+)
+
+// EnvDirSource daemontools/runit envdir 风格的来源：目录中每个文件名是键，文件内容是值
+//
+// 与 envdir 一致，默认只取第一行、去掉行尾空白、NUL 字节转换为换行，空文件表示不设置该键；
+// Raw 为 true 时使用完整内容（仅去掉末尾换行），适合密钥注入 sidecar 写入的多行值。
+// 以 . 开头的文件和子目录会被忽略。由加载器调用时读取和监听使用 Config.FS
+type EnvDirSource struct {
+	Dir string
+	Raw bool
+}
+
+var _ WatchableSource = (*EnvDirSource)(nil)
+
+// Name 返回来源名称
+func (s *EnvDirSource) Name() string { return "envdir:" + s.Dir }
+
+// Load 读取目录中的全部键值对
+func (s *EnvDirSource) Load(ctx context.Context) (map[string]string, error) {
+	fsys := sourceFS(ctx)
+	entries, err := fs.ReadDir(fsys, s.Dir)
+	if err != nil {
+		return nil, err
+	}
+	env := make(map[string]string, len(entries))
+	for _, e := range entries {
+		name := e.Name()
+		if strings.HasPrefix(name, ".") || !validKey(name) {
+			continue
+		}
+		// 跟随符号链接（Kubernetes 挂载的 Secret 即为符号链接）
+		path := filepath.Join(s.Dir, name)
+		if fi, err := fsys.Stat(path); err != nil || fi.IsDir() {
+			continue
+		}
+		data, err := fs.ReadFile(fsys, path)
+		if err != nil {
+			return nil, err
+		}
+		if len(data) == 0 {
+			continue
+		}
+		env[name] = s.value(string(data))
+	}
+	return env, nil
+}
+
+// value 按 envdir 规则处理文件内容
+func (s *EnvDirSource) value(v string) string {
+	if s.Raw {
+		return strings.TrimSuffix(strings.TrimSuffix(v, "\n"), "\r")
+	}
+	if i := strings.IndexByte(v, '\n'); i >= 0 {
+		v = v[:i]
+	}
+	v = strings.TrimRight(v, " \t")
+	return strings.ReplaceAll(v, "\x00", "\n")
+}
+
+// Watch 监听目录，文件增删改时调用 notify
+func (s *EnvDirSource) Watch(ctx context.Context, notify func()) error {
+	w, err := sourceFS(ctx).Watch()
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+	if err := w.Add(s.Dir); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case _, ok := <-w.Events():
+			if !ok {
+				return nil
+			}
+			notify()
+		case err, ok := <-w.Errors():
+			if !ok {
+				return nil
+			}
+			return err
+		}
+	}
+}
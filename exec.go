@@ -0,0 +1,56 @@
+package loadenv
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// runChangeExec 在重载生效后执行外部命令，由 finish 在后台调用；同一时间只执行一个，超过 Config.ExecTimeout 时结束子进程
+//
+// 变更摘要通过环境变量传递给子进程（只包含键名，不包含值，避免泄露敏感信息）：
+//
+//	LOADENV_FILE      环境文件的绝对路径
+//	LOADENV_ADDED     新增的键，逗号分隔
+//	LOADENV_MODIFIED  修改的键，逗号分隔
+//	LOADENV_REMOVED   删除的键，逗号分隔
+func (l *Loader) runChangeExec(changes []Change) {
+	args := l.cfg.OnChangeExec
+	var added, modified, removed []string
+	for _, c := range changes {
+		switch c.Type {
+		case Added:
+			added = append(added, c.Key)
+		case Modified:
+			modified = append(modified, c.Key)
+		case Removed:
+			removed = append(removed, c.Key)
+		}
+	}
+
+	l.execMu.Lock()
+	defer l.execMu.Unlock()
+	ctx, cancel := context.WithTimeout(context.Background(), l.cfg.ExecTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Env = append(os.Environ(),
+		"LOADENV_FILE="+l.absPath,
+		"LOADENV_ADDED="+strings.Join(added, ","),
+		"LOADENV_MODIFIED="+strings.Join(modified, ","),
+		"LOADENV_REMOVED="+strings.Join(removed, ","),
+	)
+	// 子进程被结束后，它派生的进程可能仍持有输出管道
+	cmd.WaitDelay = time.Second
+
+	out, err := cmd.CombinedOutput()
+	if ctx.Err() != nil {
+		err = ctx.Err()
+	}
+	if err != nil {
+		l.logger.Printf("Change exec %q failed: %v: %s", args[0], err, strings.TrimSpace(string(out)))
+		return
+	}
+	l.logger.Printf("Change exec %q finished", args[0])
+}
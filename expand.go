@@ -0,0 +1,18 @@
+package loadenv
+
+import "os"
+
+// Expand 将 s 中的 ${KEY} 和 $KEY 替换为快照中的值，未定义的键替换为空字符串
+func (s *Snapshot) Expand(str string) string {
+	return os.Expand(str, s.Get)
+}
+
+// Expand 使用当前配置替换 s 中的变量引用，参见 Snapshot.Expand
+func (l *Loader) Expand(s string) string {
+	return l.Snapshot().Expand(s)
+}
+
+// Expand 使用默认加载器的配置替换 s 中的变量引用，参见 Snapshot.Expand
+func Expand(s string) string {
+	return current().Expand(s)
+}
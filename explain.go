@@ -0,0 +1,106 @@
+package loadenv
+
+import "strings"
+
+// Definition 某一层对键的定义
+type Definition struct {
+	Source string `json:"source"` // file:<路径>、来源名称、pod、os、derived、transform 或 group:<分组名>
+	Value  string `json:"value"`  // 该层中的值，敏感键为 Redacted
+}
+
+// Explanation 一个键的最终值从何而来
+type Explanation struct {
+	Key         string       `json:"key"`
+	Value       string       `json:"value"`            // 最终生效的值，敏感键为 Redacted
+	Found       bool         `json:"found"`            // 键是否有值
+	Source      string       `json:"source,omitempty"` // 胜出的一层，参见 Snapshot.Source
+	Reason      string       `json:"reason"`           // 胜出的原因（优先级规则）
+	Transformed bool         `json:"transformed"`      // 值是否经过 Transformers 改写
+	Definitions []Definition `json:"definitions"`      // 所有定义了该键的层，按优先级从低到高排列
+
+	Description string `json:"description,omitempty"` // 键的说明（来自注解，参见 KeyMeta）
+}
+
+// Explain 说明键的最终值、定义了它的每一层、哪一层胜出及原因，以及是否经过转换
+//
+// 这是排查“为什么这个键是这个值”时最常用的入口；敏感键的值一律隐藏
+func (s *Snapshot) Explain(key string) Explanation {
+	e := Explanation{Key: key, Source: s.Source(key)}
+	if m, ok := s.Describe(key); ok {
+		e.Description = m.Description
+	}
+	var value string
+	value, e.Found = s.lookup(key)
+	e.Value = shown(key, value)
+	if s != nil {
+		e.Definitions = append(e.Definitions, s.defs[key]...)
+	}
+	if n := len(e.Definitions); n > 0 && e.Definitions[n-1].Source == SourceTransform {
+		e.Transformed = true
+	}
+	if e.Source == "" && e.Found {
+		e.Source = SourceOS
+		e.Definitions = append(e.Definitions, Definition{Source: SourceOS, Value: e.Value})
+	}
+	e.Reason = reason(e, s)
+	return e
+}
+
+// reason 根据胜出的一层给出优先级说明
+func reason(e Explanation, s *Snapshot) string {
+	switch {
+	case !e.Found:
+		return "not set in any layer"
+	case e.Source == SourceOS && s.Source(e.Key) == "":
+		return "not managed by the loader; read from the process environment"
+	case e.Source == SourceOS && s.preferred[e.Key]:
+		return "PreferOSEnv: the real environment always wins over files, sources and derived values"
+	case e.Source == SourceOS:
+		return "variables already in the process environment take precedence over files and sources"
+	case e.Source == SourceDerived:
+		return "derived values registered with RegisterDerived override every other layer"
+	case e.Source == SourcePod:
+		return "pod metadata is used only when no file or source defines the key"
+	case !strings.HasPrefix(e.Source, "file:"):
+		return "sources override env files, and later sources override earlier ones"
+	}
+	files := 0
+	for _, d := range e.Definitions {
+		if strings.HasPrefix(d.Source, "group:") {
+			return "group " + strings.TrimPrefix(d.Source, "group:") + " kept its previous values (static or failed validation)"
+		}
+		if strings.HasPrefix(d.Source, "file:") {
+			files++
+		}
+	}
+	if files > 1 {
+		return "later files override earlier ones: defaults file < env file < active overlays"
+	}
+	return "only defined in " + strings.TrimPrefix(e.Source, "file:")
+}
+
+// shown 返回适合展示的值，敏感键替换为 Redacted
+func shown(key, value string) string {
+	if IsSecretKey(key) && value != "" {
+		return Redacted
+	}
+	return value
+}
+
+// define 记录一层对键的定义，调用方需持有 mu
+func (l *Loader) define(key, source, value string) {
+	if l.defs == nil {
+		return
+	}
+	l.defs[key] = append(l.defs[key], Definition{Source: source, Value: shown(key, value)})
+}
+
+// Explain 参见 Snapshot.Explain
+func (l *Loader) Explain(key string) Explanation {
+	return l.Snapshot().Explain(key)
+}
+
+// Explain 说明默认加载器中键的来源，参见 Snapshot.Explain
+func Explain(key string) Explanation {
+	return current().Explain(key)
+}
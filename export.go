@@ -0,0 +1,225 @@
+package loadenv
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// ExportFormat 导出格式
+type ExportFormat string
+
+const (
+	FormatDotenv  ExportFormat = "dotenv"  // .env 格式，可被 ParseBytes 解析
+	FormatGitHub  ExportFormat = "github"  // GitHub Actions $GITHUB_ENV / $GITHUB_OUTPUT 文件格式
+	FormatCI      ExportFormat = "ci"      // 适合打印到 CI 日志的 KEY=value，敏感值替换为 ***
+	FormatCompose ExportFormat = "compose" // Docker Compose env_file 格式，值不会被 Compose 再次插值
+	FormatK8s     ExportFormat = "k8s"     // Kubernetes ConfigMap 与 Secret 清单，参见 ExportKubernetes
+	FormatTFVars  ExportFormat = "tfvars"  // Terraform .tfvars 文件，变量名为小写的键（去掉 TF_VAR_ 前缀）
+)
+
+// Export 以指定格式输出键值对，键按字母排序
+func Export(w io.Writer, env map[string]string, format ExportFormat) error {
+	switch format {
+	case FormatDotenv, "":
+		_, err := w.Write(Marshal(env))
+		return err
+	case FormatGitHub:
+		return exportGitHub(w, env)
+	case FormatCI:
+		return exportCI(w, env)
+	case FormatCompose:
+		return exportCompose(w, env)
+	case FormatK8s:
+		return ExportKubernetes(w, env, "app-config", "")
+	case FormatTFVars:
+		return exportTFVars(w, env)
+	}
+	return fmt.Errorf("loadenv: unknown export format %q", format)
+}
+
+// exportGitHub 使用 heredoc 语法（KEY<<DELIM），多行值也能正确写入
+func exportGitHub(w io.Writer, env map[string]string) error {
+	for _, k := range sortedKeys(env) {
+		v := env[k]
+		if !strings.ContainsAny(v, "\r\n") {
+			if _, err := fmt.Fprintf(w, "%s=%s\n", k, v); err != nil {
+				return err
+			}
+			continue
+		}
+		delim, err := heredocDelimiter(v)
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(w, "%s<<%s\n%s\n%s\n", k, delim, v, delim); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func exportCI(w io.Writer, env map[string]string) error {
+	for _, k := range sortedKeys(env) {
+		v := env[k]
+		if IsSecretKey(k) {
+			v = Redacted
+		}
+		if _, err := fmt.Fprintf(w, "%s=%s\n", k, quoteValue(v)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// exportCompose 简单值原样输出；含特殊字符的值用单引号包裹（Compose 中单引号内不做插值），
+// 含单引号或换行的值用双引号并转义 $ 为 $$
+func exportCompose(w io.Writer, env map[string]string) error {
+	for _, k := range sortedKeys(env) {
+		v := env[k]
+		switch {
+		case v == "" || !strings.ContainsAny(v, " \t\r\n\"'`\\$#"):
+		case !strings.ContainsAny(v, "'\r\n"):
+			v = "'" + v + "'"
+		default:
+			v = `"` + composeEscaper.Replace(v) + `"`
+		}
+		if _, err := fmt.Fprintf(w, "%s=%s\n", k, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ExportKubernetes 输出名为 name 的 ConfigMap 和 Secret 清单（YAML，以 --- 分隔），namespace 为空时省略
+//
+// 敏感键（参见 IsSecretKey）放入 Secret 并以 base64 编码，其余放入 ConfigMap；
+// 没有键的一方不输出。两者都可以通过 envFrom 整体注入容器
+func ExportKubernetes(w io.Writer, env map[string]string, name, namespace string) error {
+	plain, secret := make(map[string]string), make(map[string]string)
+	for k, v := range env {
+		if IsSecretKey(k) {
+			secret[k] = base64.StdEncoding.EncodeToString([]byte(v))
+		} else {
+			plain[k] = v
+		}
+	}
+
+	var b strings.Builder
+	manifest := func(kind string, data map[string]string) {
+		if b.Len() > 0 {
+			b.WriteString("---\n")
+		}
+		fmt.Fprintf(&b, "apiVersion: v1\nkind: %s\nmetadata:\n  name: %s\n", kind, yamlString(name))
+		if namespace != "" {
+			fmt.Fprintf(&b, "  namespace: %s\n", yamlString(namespace))
+		}
+		if kind == "Secret" {
+			b.WriteString("type: Opaque\n")
+		}
+		if len(data) == 0 {
+			b.WriteString("data: {}\n")
+			return
+		}
+		b.WriteString("data:\n")
+		for _, k := range sortedKeys(data) {
+			fmt.Fprintf(&b, "  %s: %s\n", k, yamlString(data[k]))
+		}
+	}
+	if len(plain) > 0 || len(secret) == 0 {
+		manifest("ConfigMap", plain)
+	}
+	if len(secret) > 0 {
+		manifest("Secret", secret)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// yamlString 将字符串编码为 YAML 双引号标量（JSON 字符串是合法的 YAML）
+func yamlString(s string) string {
+	b, _ := json.Marshal(s)
+	return string(b)
+}
+
+// exportTFVars 输出 name = "value"，值按 HCL 字符串规则转义，${ 和 %{ 转义为字面量不做模板插值
+//
+// 变量名由键名去掉 TF_VAR_ 前缀、转为小写并把 . 替换为 _ 得到；两个键得到同一个变量名时返回错误
+func exportTFVars(w io.Writer, env map[string]string) error {
+	vars := make(map[string]string, len(env))
+	from := make(map[string]string, len(env))
+	for _, k := range sortedKeys(env) {
+		name := strings.ToLower(strings.TrimPrefix(k, "TF_VAR_"))
+		name = strings.ReplaceAll(name, ".", "_")
+		if prev, ok := from[name]; ok {
+			return fmt.Errorf("loadenv: keys %s and %s both map to tfvars variable %q", prev, k, name)
+		}
+		vars[name], from[name] = env[k], k
+	}
+	for _, k := range sortedKeys(vars) {
+		if _, err := fmt.Fprintf(w, "%s = \"%s\"\n", k, tfvarsEscaper.Replace(vars[k])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var tfvarsEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`, "\t", `\t`, "${", "$${", "%{", "%%{")
+
+var composeEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`, "$", "$$")
+
+// HashEnv 返回键值对的稳定哈希（sha256 十六进制），与键的顺序无关，
+// 可作为部署注解（例如 checksum/config），配置变化时触发容器重启
+func HashEnv(env map[string]string) string {
+	h := sha256.New()
+	for _, k := range sortedKeys(env) {
+		// 以长度作前缀，避免 "A=1"+"B" 与 "A=1B" 之类的拼接歧义
+		fmt.Fprintf(h, "%d:%s%d:%s", len(k), k, len(env[k]), env[k])
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// GitHubMasks 返回为敏感值生成的 ::add-mask:: 工作流命令，打印到标准输出后 GitHub 会在日志中隐藏这些值
+func GitHubMasks(env map[string]string) []string {
+	var masks []string
+	for _, k := range sortedKeys(env) {
+		if !IsSecretKey(k) || env[k] == "" {
+			continue
+		}
+		for _, line := range strings.Split(env[k], "\n") {
+			if line = strings.TrimRight(line, "\r"); line != "" {
+				masks = append(masks, "::add-mask::"+line)
+			}
+		}
+	}
+	return masks
+}
+
+// heredocDelimiter 生成不会出现在值中的随机分隔符
+func heredocDelimiter(v string) (string, error) {
+	for {
+		b := make([]byte, 8)
+		if _, err := rand.Read(b); err != nil {
+			return "", err
+		}
+		d := "ghadelimiter_" + hex.EncodeToString(b)
+		if !strings.Contains(v, d) {
+			return d, nil
+		}
+	}
+}
+
+func sortedKeys(env map[string]string) []string {
+	keys := make([]string, 0, len(env))
+	for k := range env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
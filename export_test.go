@@ -0,0 +1,37 @@
+package loadenv
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestExportTFVarsCollision(t *testing.T) {
+	for _, env := range []map[string]string{
+		{"FOO": "1", "foo": "2"},
+		{"TF_VAR_FOO": "1", "FOO": "2"},
+		{"A.B": "1", "A_B": "2"},
+	} {
+		var buf bytes.Buffer
+		err := Export(&buf, env, FormatTFVars)
+		if err == nil {
+			t.Fatalf("%v: expected a collision error, got output %q", env, buf.String())
+		}
+		for key := range env {
+			if !strings.Contains(err.Error(), key) {
+				t.Errorf("%v: error %q does not name %s", env, err, key)
+			}
+		}
+		if buf.Len() != 0 {
+			t.Errorf("%v: partial output %q", env, buf.String())
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := Export(&buf, map[string]string{"TF_VAR_REGION": "eu", "app.name": "x"}, FormatTFVars); err != nil {
+		t.Fatal(err)
+	}
+	if want := "app_name = \"x\"\nregion = \"eu\"\n"; buf.String() != want {
+		t.Fatalf("got %q, want %q", buf.String(), want)
+	}
+}
@@ -0,0 +1,134 @@
+package loadenv
+
+import (
+	"fmt"
+	"net/mail"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Format 值的格式约束
+type Format struct {
+	Name  string            // 格式名称，用于错误信息
+	Check func(string) bool // 返回值是否符合格式
+}
+
+// 内置格式
+var (
+	FormatEmail    = Format{Name: "email address", Check: isEmail}
+	FormatUUID     = Format{Name: "UUID", Check: uuidRe.MatchString}
+	FormatHostname = Format{Name: "hostname", Check: isHostname}
+	FormatPort     = Format{Name: "port (1-65535)", Check: isPort}
+)
+
+var uuidRe = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// Pattern 返回要求值完整匹配正则表达式 expr 的格式，expr 非法时 panic
+func Pattern(expr string) Format {
+	re := regexp.MustCompile(`^(?:` + expr + `)$`)
+	return Format{Name: fmt.Sprintf("value matching /%s/", expr), Check: re.MatchString}
+}
+
+// validate 校验 key 的值是否符合格式
+func (f Format) validate(key, value string) error {
+	if f.Check(value) {
+		return nil
+	}
+	return &ValueError{Key: key, Value: value, Want: f.Name}
+}
+
+// checkFormats 按 Config.Formats 校验即将加载的变量，只检查存在的键；返回所有不符合的键（按键排序）
+func checkFormats(formats map[string]Format, env map[string]string) error {
+	keys := make([]string, 0, len(formats))
+	for key := range formats {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var errs []error
+	for _, key := range keys {
+		if v, ok := env[key]; ok {
+			errs = append(errs, formats[key].validate(key, v))
+		}
+	}
+	return joinErrors(errs...)
+}
+
+// GetMatched 返回键的值，要求完整匹配正则表达式 pattern
+func (s *Snapshot) GetMatched(key, pattern string) (string, error) {
+	v, err := s.require(key)
+	if err != nil {
+		return "", err
+	}
+	re, err := regexp.Compile(`^(?:` + pattern + `)$`)
+	if err != nil {
+		return "", err
+	}
+	if !re.MatchString(v) {
+		return "", &ValueError{Key: key, Value: v, Want: fmt.Sprintf("value matching /%s/", pattern)}
+	}
+	return v, nil
+}
+
+// GetFormat 返回键的值，要求符合格式 f
+func (s *Snapshot) GetFormat(key string, f Format) (string, error) {
+	v, err := s.require(key)
+	if err != nil {
+		return "", err
+	}
+	if err := f.validate(key, v); err != nil {
+		return "", err
+	}
+	return v, nil
+}
+
+// GetMatched 参见 Snapshot.GetMatched
+func (l *Loader) GetMatched(key, pattern string) (string, error) {
+	return l.Snapshot().GetMatched(key, pattern)
+}
+
+// GetFormat 参见 Snapshot.GetFormat
+func (l *Loader) GetFormat(key string, f Format) (string, error) {
+	return l.Snapshot().GetFormat(key, f)
+}
+
+// GetMatched 从默认加载器读取并校验，参见 Snapshot.GetMatched
+func GetMatched(key, pattern string) (string, error) {
+	return current().GetMatched(key, pattern)
+}
+
+// GetFormat 从默认加载器读取并校验，参见 Snapshot.GetFormat
+func GetFormat(key string, f Format) (string, error) {
+	return current().GetFormat(key, f)
+}
+
+func isEmail(s string) bool {
+	addr, err := mail.ParseAddress(s)
+	return err == nil && addr.Address == s
+}
+
+// isHostname 按 RFC 1123 校验主机名
+func isHostname(s string) bool {
+	s = strings.TrimSuffix(s, ".")
+	if s == "" || len(s) > 253 {
+		return false
+	}
+	for _, label := range strings.Split(s, ".") {
+		if label == "" || len(label) > 63 || label[0] == '-' || label[len(label)-1] == '-' {
+			return false
+		}
+		for i := 0; i < len(label); i++ {
+			c := label[i]
+			if !isDigit(c) && !(c >= 'a' && c <= 'z') && !(c >= 'A' && c <= 'Z') && c != '-' {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func isPort(s string) bool {
+	n, err := strconv.Atoi(s)
+	return err == nil && n >= 1 && n <= 65535
+}
@@ -0,0 +1,51 @@
+package loadenv
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrFrozen 配置已冻结，不再接受重载
+var ErrFrozen = errors.New("loadenv: config is frozen")
+
+// Freeze 冻结配置：之后的文件事件、来源通知和 Reload 调用都不再生效，当前快照保持不变
+func (l *Loader) Freeze() {
+	if l.frozen.Swap(true) {
+		return
+	}
+	l.stopDebounce()
+	l.logger.Printf("Config frozen, hot reload disabled")
+}
+
+// stopDebounce 取消已安排的去抖重载
+func (l *Loader) stopDebounce() {
+	l.debounceMu.Lock()
+	if l.timer != nil {
+		l.timer.Stop()
+	}
+	l.debounceMu.Unlock()
+}
+
+// FreezeAfter 在 d 之后冻结配置，通常在启动时调用，给应用留出完成初始化的窗口
+func (l *Loader) FreezeAfter(d time.Duration) {
+	l.cfg.Clock.AfterFunc(d, l.Freeze)
+}
+
+// Frozen 返回配置是否已冻结（包括 Pin 固定期间）
+func (l *Loader) Frozen() bool {
+	return l.frozen.Load() || l.held.Load()
+}
+
+// Freeze 冻结默认加载器，参见 Loader.Freeze
+func Freeze() {
+	if l := std.Load(); l != nil {
+		l.Freeze()
+	}
+}
+
+// FreezeAfter 在 d 之后冻结默认加载器，参见 Loader.FreezeAfter
+func FreezeAfter(d time.Duration) {
+	if l := std.Load(); l != nil {
+		l.FreezeAfter(d)
+	}
+}
@@ -0,0 +1,52 @@
+package loadenv
+
+import (
+	"errors"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestFrozenRejectsChanges 冻结后 RollbackTo 和 RegisterDerived 返回 ErrFrozen，快照和进程环境变量保持不变
+func TestFrozenRejectsChanges(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+	write := func(data string) {
+		if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("FROZEN_A=1\n")
+	l, err := New(Config{FilePath: path, Logger: log.New(io.Discard, "", 0)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		l.Close()
+		os.Unsetenv("FROZEN_A")
+	})
+	first := l.Snapshot()
+	write("FROZEN_A=2\n")
+	if _, err := l.Reload(); err != nil {
+		t.Fatal(err)
+	}
+
+	l.Freeze()
+	current := l.Snapshot()
+	if _, err := l.RollbackTo(first.Checksum()); !errors.Is(err, ErrFrozen) {
+		t.Fatalf("RollbackTo: got %v, want ErrFrozen", err)
+	}
+	if err := l.RegisterDerived("FROZEN_DERIVED", func(ReadOnlyEnv) string { return "x" }); !errors.Is(err, ErrFrozen) {
+		t.Fatalf("RegisterDerived: got %v, want ErrFrozen", err)
+	}
+	if l.Snapshot() != current {
+		t.Fatal("snapshot replaced while frozen")
+	}
+	if v := os.Getenv("FROZEN_A"); v != "2" {
+		t.Fatalf("FROZEN_A=%q in process env, want 2", v)
+	}
+	if _, ok := l.Lookup("FROZEN_DERIVED"); ok {
+		t.Fatal("derived value registered while frozen")
+	}
+}
@@ -0,0 +1,54 @@
+package loadenv
+
+import (
+	"strings"
+	"time"
+)
+
+// GetFresh 返回键的值，值来自远端来源（Config.Sources）且当前快照早于 maxStale 之前生成时，先同步重载一次
+//
+// 适用于能容忍一次远端请求延迟、但需要尽量新的密钥的调用方，例如轮换频繁的数据库密码：
+//
+//	password, err := l.GetFresh("DB_PASSWORD", 30*time.Second)
+//
+// 并发调用只会触发一次重载。刷新是一次完整的重载（包括钩子和金丝雀检查）；
+// 失败时返回缓存的值和重载错误。加载器被冻结（或 Pin 固定）时直接返回缓存的值。
+// 来自环境文件的值由文件监听保持最新，不会触发重载
+func (l *Loader) GetFresh(key string, maxStale time.Duration) (string, error) {
+	snap := l.Snapshot()
+	if !l.stale(snap, key, maxStale) {
+		return snap.Get(key), nil
+	}
+
+	l.reloadMu.Lock()
+	defer l.reloadMu.Unlock()
+	// 等待期间其他调用方可能已经刷新过
+	if snap = l.Snapshot(); !l.stale(snap, key, maxStale) || l.Frozen() {
+		return snap.Get(key), nil
+	}
+	_, err := l.reload(0, l.cfg.Clock.Now())
+	return l.Get(key), err
+}
+
+// stale 判断 snap 中键的值是否来自远端来源且已超过 maxStale
+func (l *Loader) stale(snap *Snapshot, key string, maxStale time.Duration) bool {
+	if len(l.cfg.Sources) == 0 || l.cfg.Clock.Now().Sub(snap.LoadedAt()) <= maxStale {
+		return false
+	}
+	switch origin := snap.Source(key); {
+	case origin == "":
+		// 尚未定义的键可能刚刚在远端添加
+		return true
+	case origin == SourceOS, origin == SourcePod, origin == SourceDerived, strings.HasPrefix(origin, "file:"):
+		return false
+	}
+	return true
+}
+
+// GetFresh 在默认加载器上读取，参见 Loader.GetFresh；未初始化时读取进程环境变量
+func GetFresh(key string, maxStale time.Duration) (string, error) {
+	if l := std.Load(); l != nil {
+		return l.GetFresh(key, maxStale)
+	}
+	return current().Get(key), nil
+}
@@ -0,0 +1,54 @@
+package loadenv
+
+import (
+	"io/fs"
+	"os"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// FS 加载器访问文件系统的接口，嵌入方可以通过它接入叠加文件系统、内存文件系统等
+//
+// 名称均为操作系统的绝对路径（不要求满足 fs.ValidPath）；
+// FS 同时满足 fs.FS，可以直接用于 fs.ReadFile、fs.ReadDir 等函数
+type FS interface {
+	Open(name string) (fs.File, error)
+	Stat(name string) (fs.FileInfo, error)
+	Watch() (Watcher, error) // 创建一个新的监听器
+}
+
+// Watcher 文件变更监听器，语义与 fsnotify.Watcher 相同
+type Watcher interface {
+	Add(name string) error
+	WatchList() []string
+	Events() <-chan fsnotify.Event
+	Errors() <-chan error
+	Close() error
+}
+
+// osFS 基于本地文件系统和 fsnotify 的默认实现
+type osFS struct{}
+
+func (osFS) Open(name string) (fs.File, error) { return os.Open(name) }
+
+func (osFS) Stat(name string) (fs.FileInfo, error) { return os.Stat(name) }
+
+func (osFS) Watch() (Watcher, error) {
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	return fsnotifyWatcher{w}, nil
+}
+
+// fsnotifyWatcher 将 *fsnotify.Watcher 的通道字段适配为 Watcher 接口
+type fsnotifyWatcher struct {
+	*fsnotify.Watcher
+}
+
+func (w fsnotifyWatcher) Events() <-chan fsnotify.Event { return w.Watcher.Events }
+
+func (w fsnotifyWatcher) Errors() <-chan error { return w.Watcher.Errors }
+
+// OSFS 返回默认的本地文件系统实现，可作为自定义 FS 的底层
+func OSFS() FS { return osFS{} }
@@ -0,0 +1,88 @@
+package loadenv
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrNotSet 键未设置
+var ErrNotSet = errors.New("loadenv: key not set")
+
+// ValueError 键存在但值不符合预期格式
+type ValueError struct {
+	Key   string
+	Value string
+	Want  string // 期望的格式描述
+	Err   error  // 底层解析错误，可为 nil
+
+	Description string // 键的说明（来自注解，参见 KeyMeta），可为空
+}
+
+func (e *ValueError) Error() string {
+	msg := fmt.Sprintf("loadenv: %s=%q is not a valid %s", e.Key, e.Value, e.Want)
+	if e.Description != "" {
+		msg = fmt.Sprintf("loadenv: %s (%s): value %q is not a valid %s", e.Key, e.Description, e.Value, e.Want)
+	}
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+func (e *ValueError) Unwrap() error { return e.Err }
+
+// require 返回键的值，未设置时返回包装了 ErrNotSet 的错误
+func (s *Snapshot) require(key string) (string, error) {
+	v, ok := s.Lookup(key)
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrNotSet, key)
+	}
+	return v, nil
+}
+
+// GetTime 按 layout 解析键的值为时间，例如 GetTime("MAINTENANCE_START", time.RFC3339)
+func (s *Snapshot) GetTime(key, layout string) (time.Time, error) {
+	v, err := s.require(key)
+	if err != nil {
+		return time.Time{}, err
+	}
+	t, err := time.Parse(layout, v)
+	if err != nil {
+		return time.Time{}, &ValueError{Key: key, Value: v, Want: fmt.Sprintf("time in layout %q", layout), Err: err}
+	}
+	return t, nil
+}
+
+// GetLocation 将键的值解析为时区，例如 GetLocation("TZ")，支持 "UTC"、"Local" 和 IANA 名称
+func (s *Snapshot) GetLocation(key string) (*time.Location, error) {
+	v, err := s.require(key)
+	if err != nil {
+		return nil, err
+	}
+	loc, err := time.LoadLocation(v)
+	if err != nil {
+		return nil, &ValueError{Key: key, Value: v, Want: "IANA time zone name (e.g. Asia/Shanghai)", Err: err}
+	}
+	return loc, nil
+}
+
+// GetTime 参见 Snapshot.GetTime
+func (l *Loader) GetTime(key, layout string) (time.Time, error) {
+	return l.Snapshot().GetTime(key, layout)
+}
+
+// GetLocation 参见 Snapshot.GetLocation
+func (l *Loader) GetLocation(key string) (*time.Location, error) {
+	return l.Snapshot().GetLocation(key)
+}
+
+// GetTime 从默认加载器读取时间，参见 Snapshot.GetTime
+func GetTime(key, layout string) (time.Time, error) {
+	return current().GetTime(key, layout)
+}
+
+// GetLocation 从默认加载器读取时区，参见 Snapshot.GetLocation
+func GetLocation(key string) (*time.Location, error) {
+	return current().GetLocation(key)
+}
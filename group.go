@@ -0,0 +1,147 @@
+package loadenv
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+)
+
+// GetGroup 返回所有以 prefix 开头的键，结果中的键去掉了前缀
+//
+// 例如 SMTP_HOST、SMTP_PORT 调用 GetGroup("SMTP_") 得到 {"HOST": ..., "PORT": ...}。
+// 环境文件中的值优先于进程环境变量
+func (s *Snapshot) GetGroup(prefix string) map[string]string {
+	group := make(map[string]string)
+	if s != nil && s.isolated {
+		return s.groupOf(prefix, group)
+	}
+	for _, kv := range os.Environ() {
+		if k, v, ok := strings.Cut(kv, "="); ok && strings.HasPrefix(k, prefix) && len(k) > len(prefix) {
+			group[k[len(prefix):]] = v
+		}
+	}
+	if s == nil {
+		return group
+	}
+	return s.groupOf(prefix, group)
+}
+
+// groupOf 将快照中以 prefix 开头的键加入 group
+func (s *Snapshot) groupOf(prefix string, group map[string]string) map[string]string {
+	for k, v := range s.env {
+		if strings.HasPrefix(k, prefix) && len(k) > len(prefix) {
+			group[k[len(prefix):]] = s.value(k, v)
+		}
+	}
+	return group
+}
+
+// GetGroup 返回当前配置中所有以 prefix 开头的键，参见 Snapshot.GetGroup
+func (l *Loader) GetGroup(prefix string) map[string]string {
+	return l.Snapshot().GetGroup(prefix)
+}
+
+// GetGroup 返回默认加载器中所有以 prefix 开头的键，参见 Snapshot.GetGroup
+func GetGroup(prefix string) map[string]string {
+	return current().GetGroup(prefix)
+}
+
+// Group 一组相关的键（如 logging、limits、credentials），拥有独立的校验、回调和重载策略
+//
+// 键属于 Keys 中列出的键或以 Prefix 开头的键；同一个键可以属于多个分组。
+// 重载时分组校验失败只会保留该分组的原值，其他分组照常更新；首次加载时校验失败则加载失败
+type Group struct {
+	Name     string
+	Prefix   string
+	Keys     []string
+	Static   bool                                 // 不参与热重载，始终保持首次加载时的值
+	Validate func(values map[string]string) error // 校验分组内的键值，参数中的键不去掉前缀
+	OnChange func(changes []Change)               // 重载后分组内的键发生变化时调用，在重载协程中同步执行
+}
+
+// GroupError 分组校验失败
+type GroupError struct {
+	Group string
+	Err   error
+}
+
+func (e *GroupError) Error() string {
+	return fmt.Sprintf("loadenv: group %s: %v", e.Group, e.Err)
+}
+
+func (e *GroupError) Unwrap() error { return e.Err }
+
+// contains 判断键是否属于分组
+func (g *Group) contains(key string) bool {
+	return (g.Prefix != "" && strings.HasPrefix(key, g.Prefix)) || slices.Contains(g.Keys, key)
+}
+
+// members 返回 env 中属于分组的键值
+func (g *Group) members(env map[string]string) map[string]string {
+	m := make(map[string]string)
+	for k, v := range env {
+		if g.contains(k) {
+			m[k] = v
+		}
+	}
+	return m
+}
+
+// applyGroups 按分组策略处理新读取的 env：静态分组和校验失败的分组恢复为上次加载的值，调用方需持有 mu
+func (l *Loader) applyGroups(env, origins map[string]string) error {
+	first := l.last == nil
+	prev := l.snapshot.Load()
+	for i := range l.cfg.Groups {
+		g := &l.cfg.Groups[i]
+		if first || !g.Static {
+			if g.Validate == nil {
+				continue
+			}
+			err := g.Validate(g.members(env))
+			if err == nil {
+				continue
+			}
+			err = &GroupError{Group: g.Name, Err: err}
+			if first {
+				return err
+			}
+			l.logger.Printf("%v (keeping previous values)", err)
+		}
+
+		// 恢复分组内的键为上次加载的值
+		for k := range env {
+			if _, ok := l.last[k]; !ok && g.contains(k) {
+				delete(env, k)
+				delete(origins, k)
+			}
+		}
+		for k, v := range l.last {
+			if g.contains(k) {
+				env[k] = v
+				origins[k] = prev.Source(k)
+				l.define(k, "group:"+g.Name, v)
+			}
+		}
+	}
+	return nil
+}
+
+// notifyGroups 将一次重载的变化按分组分发给 OnChange
+func (l *Loader) notifyGroups(changes []Change) {
+	for i := range l.cfg.Groups {
+		g := &l.cfg.Groups[i]
+		if g.OnChange == nil {
+			continue
+		}
+		var own []Change
+		for _, c := range changes {
+			if g.contains(c.Key) {
+				own = append(own, c)
+			}
+		}
+		if len(own) > 0 {
+			g.OnChange(own)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package loadenv
+
+import "time"
+
+// Heartbeat 一次心跳报告的内容
+type Heartbeat struct {
+	Checksum string        // 当前运行的配置校验和，参见 Snapshot.Checksum
+	Version  string        // 校验和的前 12 位，与 X-Config-Version 一致，适合作为指标标签
+	LoadedAt time.Time     // 当前配置生效的时间
+	Age      time.Duration // 当前配置已运行的时长
+	Frozen   bool          // 配置是否已冻结
+	Stats    Stats         // 重载统计
+}
+
+// heartbeat 生成当前的心跳报告
+func (l *Loader) heartbeat() Heartbeat {
+	snap := l.Snapshot()
+	return Heartbeat{
+		Checksum: snap.Checksum(),
+		Version:  snap.ShortChecksum(),
+		LoadedAt: snap.LoadedAt(),
+		Age:      l.cfg.Clock.Now().Sub(snap.LoadedAt()),
+		Frozen:   l.Frozen(),
+		Stats:    l.Stats(),
+	}
+}
+
+// scheduleHeartbeat 按 HeartbeatInterval 周期性调用 OnHeartbeat，加载器关闭后停止
+func (l *Loader) scheduleHeartbeat() {
+	l.cfg.Clock.AfterFunc(l.cfg.HeartbeatInterval, func() {
+		if l.isClosed() {
+			return
+		}
+		l.cfg.OnHeartbeat(l.heartbeat())
+		l.scheduleHeartbeat()
+	})
+}
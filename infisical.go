@@ -0,0 +1,90 @@
+package loadenv
+
+import (
+	"context"
+	"crypto/hmac"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// InfisicalSource 从 Infisical 读取某个项目环境下的密钥
+//
+// 变更通过轮询（PollInterval）发现，也可以把 WebhookHandler 挂到 HTTP 服务上接收 Infisical webhook 以立即生效
+type InfisicalSource struct {
+	Token         string        // 访问令牌（Universal Auth 等方式获取）
+	ProjectID     string        // 项目（workspace）ID
+	Environment   string        // 环境标识，例如 prod
+	Path          string        // 密钥路径，默认 /
+	PollInterval  time.Duration // 轮询间隔，默认 1 分钟
+	WebhookSecret string        // webhook 签名密钥，为空时不校验
+	BaseURL       string        // API 地址，默认 https://app.infisical.com
+	Client        *http.Client  // 发送请求的客户端（代理、CA、超时等），默认 http.DefaultClient
+
+	poller
+}
+
+var _ WatchableSource = (*InfisicalSource)(nil)
+
+// Name 返回来源名称
+func (s *InfisicalSource) Name() string { return "infisical:" + s.ProjectID + "/" + s.Environment }
+
+// Load 读取全部密钥
+func (s *InfisicalSource) Load(ctx context.Context) (map[string]string, error) {
+	base := s.BaseURL
+	if base == "" {
+		base = "https://app.infisical.com"
+	}
+	path := s.Path
+	if path == "" {
+		path = "/"
+	}
+	q := url.Values{
+		"workspaceId": {s.ProjectID},
+		"environment": {s.Environment},
+		"secretPath":  {path},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, base+"/api/v3/secrets/raw?"+q.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+s.Token)
+
+	var body struct {
+		Secrets []struct {
+			Key   string `json:"secretKey"`
+			Value string `json:"secretValue"`
+		} `json:"secrets"`
+	}
+	if err := doJSON(s.Client, req, &body); err != nil {
+		return nil, err
+	}
+	env := make(map[string]string, len(body.Secrets))
+	for _, sec := range body.Secrets {
+		env[sec.Key] = sec.Value
+	}
+	return env, nil
+}
+
+// Watch 轮询或在收到 webhook 时检查变更
+func (s *InfisicalSource) Watch(ctx context.Context, notify func()) error {
+	return s.watch(ctx, s.Name(), s.PollInterval, s.Load, notify)
+}
+
+// WebhookHandler 返回接收 Infisical webhook 的处理器，校验 x-infisical-signature 头（t=<时间戳>;<签名>）
+func (s *InfisicalSource) WebhookHandler() http.Handler {
+	var verify func(*http.Request, []byte) bool
+	if s.WebhookSecret != "" {
+		verify = func(r *http.Request, body []byte) bool {
+			ts, sig, ok := strings.Cut(r.Header.Get("X-Infisical-Signature"), ";")
+			if !ok {
+				return false
+			}
+			ts = strings.TrimPrefix(ts, "t=")
+			return hmac.Equal([]byte(sig), []byte(hmacSHA256Hex(s.WebhookSecret, []byte(ts+"."+string(body)))))
+		}
+	}
+	return s.webhookHandler(verify)
+}
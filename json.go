@@ -0,0 +1,80 @@
+package loadenv
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// GetJSON 将键的值按 JSON 解码到 v，例如 GetJSON("FEATURES_JSON", &features)
+func (s *Snapshot) GetJSON(key string, v any) error {
+	raw, err := s.require(key)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal([]byte(raw), v); err != nil {
+		return &ValueError{Key: key, Value: raw, Want: "JSON", Err: err}
+	}
+	return nil
+}
+
+// GetJSONPath 解析键的 JSON 值并返回 path 指向的元素，例如 GetJSONPath("CONFIG_JSON", "limits.max")
+//
+// path 以点分隔，对象按字段名查找，数组按下标查找（如 "servers.0.host"）；
+// 空 path 返回整个值。返回值的类型与 encoding/json 解码到 any 时相同
+func (s *Snapshot) GetJSONPath(key, path string) (any, error) {
+	var doc any
+	if err := s.GetJSON(key, &doc); err != nil {
+		return nil, err
+	}
+	if path == "" {
+		return doc, nil
+	}
+	cur := doc
+	for i, seg := range strings.Split(path, ".") {
+		switch node := cur.(type) {
+		case map[string]any:
+			v, ok := node[seg]
+			if !ok {
+				return nil, jsonPathError(key, path, i)
+			}
+			cur = v
+		case []any:
+			n, err := strconv.Atoi(seg)
+			if err != nil || n < 0 || n >= len(node) {
+				return nil, jsonPathError(key, path, i)
+			}
+			cur = node[n]
+		default:
+			return nil, jsonPathError(key, path, i)
+		}
+	}
+	return cur, nil
+}
+
+// jsonPathError 报告 path 在第 i 段处无法继续解析
+func jsonPathError(key, path string, i int) error {
+	segs := strings.Split(path, ".")
+	return fmt.Errorf("%w: %s: JSON path %q (at %q)", ErrNotSet, key, path, strings.Join(segs[:i+1], "."))
+}
+
+// GetJSON 参见 Snapshot.GetJSON
+func (l *Loader) GetJSON(key string, v any) error {
+	return l.Snapshot().GetJSON(key, v)
+}
+
+// GetJSONPath 参见 Snapshot.GetJSONPath
+func (l *Loader) GetJSONPath(key, path string) (any, error) {
+	return l.Snapshot().GetJSONPath(key, path)
+}
+
+// GetJSON 从默认加载器读取 JSON 值，参见 Snapshot.GetJSON
+func GetJSON(key string, v any) error {
+	return current().GetJSON(key, v)
+}
+
+// GetJSONPath 从默认加载器读取 JSON 路径，参见 Snapshot.GetJSONPath
+func GetJSONPath(key, path string) (any, error) {
+	return current().GetJSONPath(key, path)
+}
@@ -0,0 +1,275 @@
+package loadenv
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 集群内 ServiceAccount 凭据的默认位置
+const (
+	serviceAccountDir = "/var/run/secrets/kubernetes.io/serviceaccount"
+)
+
+// KubernetesSource 通过 Kubernetes API 读取并实时监听一个 ConfigMap 或 Secret
+//
+// 直接使用 API 的 watch 接口而不是 kubelet 挂载的文件，变更几乎立即生效。
+// 默认使用 Pod 内的 ServiceAccount 凭据，需要对目标对象有 get/watch 权限
+type KubernetesSource struct {
+	Namespace string // 命名空间，默认为 Pod 所在命名空间
+	Object    string // 对象名称
+	Secret    bool   // 为 true 时读取 Secret，否则读取 ConfigMap
+
+	APIServer string // API 地址，默认从 KUBERNETES_SERVICE_HOST/PORT 推导
+	TokenFile string // 令牌文件，默认 ServiceAccount 令牌
+	CAFile    string // CA 证书文件，默认 ServiceAccount CA
+
+	// Client 访问 API 的基础客户端（代理、超时等），默认 http.DefaultClient；
+	// 其 TLS 设置会被替换为信任 CAFile。watch 是长连接，不要设置 Client.Timeout
+	Client *http.Client
+
+	once   sync.Once
+	client *http.Client
+	err    error
+
+	mu      sync.Mutex
+	version string // 最近一次读到或监听到的 resourceVersion
+}
+
+var _ WatchableSource = (*KubernetesSource)(nil)
+
+// Name 返回来源名称
+func (s *KubernetesSource) Name() string {
+	return "kubernetes:" + s.resource() + "/" + s.namespace() + "/" + s.Object
+}
+
+func (s *KubernetesSource) resource() string {
+	if s.Secret {
+		return "secrets"
+	}
+	return "configmaps"
+}
+
+func (s *KubernetesSource) namespace() string {
+	if s.Namespace != "" {
+		return s.Namespace
+	}
+	if ns, err := os.ReadFile(serviceAccountDir + "/namespace"); err == nil {
+		return strings.TrimSpace(string(ns))
+	}
+	return "default"
+}
+
+// init 构建访问 API 的 HTTP 客户端，CA 文件通过 ctx 携带的加载器的 Config.FS 读取
+func (s *KubernetesSource) init(ctx context.Context) error {
+	s.once.Do(func() {
+		if s.APIServer == "" {
+			host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+			if host == "" || port == "" {
+				s.err = errors.New("not running in a cluster: KUBERNETES_SERVICE_HOST is not set and APIServer is empty")
+				return
+			}
+			s.APIServer = "https://" + net.JoinHostPort(host, port)
+		}
+		if s.TokenFile == "" {
+			s.TokenFile = serviceAccountDir + "/token"
+		}
+		if s.CAFile == "" {
+			s.CAFile = serviceAccountDir + "/ca.crt"
+		}
+
+		ca, err := fs.ReadFile(sourceFS(ctx), s.CAFile)
+		if err != nil {
+			s.err = err
+			return
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			s.err = fmt.Errorf("no certificates found in %s", s.CAFile)
+			return
+		}
+		s.client, s.err = tlsClient(s.Client, func(c *tls.Config) { c.RootCAs = pool })
+	})
+	return s.err
+}
+
+// newRequest 创建带认证头的请求，每次重新读取令牌以支持令牌轮换
+func (s *KubernetesSource) newRequest(ctx context.Context, path string, q url.Values) (*http.Request, error) {
+	token, err := fs.ReadFile(sourceFS(ctx), s.TokenFile)
+	if err != nil {
+		return nil, err
+	}
+	u := s.APIServer + path
+	if len(q) > 0 {
+		u += "?" + q.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(string(token)))
+	req.Header.Set("Accept", "application/json")
+	return req, nil
+}
+
+// k8sObject ConfigMap 与 Secret 共有的字段
+type k8sObject struct {
+	Metadata struct {
+		ResourceVersion string `json:"resourceVersion"`
+	} `json:"metadata"`
+	Data map[string]string `json:"data"`
+}
+
+// env 返回对象中的键值对，Secret 的值需要 base64 解码
+func (s *KubernetesSource) env(obj *k8sObject) (map[string]string, error) {
+	env := make(map[string]string, len(obj.Data))
+	for k, v := range obj.Data {
+		if s.Secret {
+			b, err := base64.StdEncoding.DecodeString(v)
+			if err != nil {
+				return nil, fmt.Errorf("decode %s: %w", k, err)
+			}
+			v = string(b)
+		}
+		env[k] = v
+	}
+	return env, nil
+}
+
+// seen 记录最近看到的 resourceVersion，返回之前记录的值
+func (s *KubernetesSource) seen(version string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	prev := s.version
+	s.version = version
+	return prev
+}
+
+// get 读取对象
+func (s *KubernetesSource) get(ctx context.Context) (*k8sObject, error) {
+	if err := s.init(ctx); err != nil {
+		return nil, err
+	}
+	req, err := s.newRequest(ctx, "/api/v1/namespaces/"+s.namespace()+"/"+s.resource()+"/"+s.Object, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("get %s: %s", s.Object, resp.Status)
+	}
+	var obj k8sObject
+	if err := json.NewDecoder(resp.Body).Decode(&obj); err != nil {
+		return nil, err
+	}
+	return &obj, nil
+}
+
+// errWatchExpired watch 的 resourceVersion 已过期，需要重新 get
+var errWatchExpired = errors.New("watch expired")
+
+// Load 读取对象中的全部键值对
+func (s *KubernetesSource) Load(ctx context.Context) (map[string]string, error) {
+	obj, err := s.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s.seen(obj.Metadata.ResourceVersion)
+	return s.env(obj)
+}
+
+// Watch 通过 watch 接口监听对象变更，连接断开后自动重连（失败时退避，最长间隔 30 秒）
+//
+// 重连时重新 get 对象，resourceVersion 与断开前最后看到的不同时调用 notify，断开期间的变更不会丢失。
+// 读取和监听失败通过加载器的日志记录器记录
+func (s *KubernetesSource) Watch(ctx context.Context, notify func()) error {
+	logger, clock := sourceLogger(ctx), sourceClock(ctx)
+	backoff := time.Second
+	for ctx.Err() == nil {
+		obj, err := s.get(ctx)
+		if err == nil {
+			if prev := s.seen(obj.Metadata.ResourceVersion); prev != "" && prev != obj.Metadata.ResourceVersion {
+				notify()
+			}
+			var connected bool
+			connected, err = s.watchFrom(ctx, obj.Metadata.ResourceVersion, notify)
+			if connected {
+				backoff = time.Second
+			}
+		}
+		if ctx.Err() != nil {
+			return nil
+		}
+		// 服务端正常结束或 resourceVersion 过期不是错误，稍后重新 get 并监听
+		if err != nil && !errors.Is(err, errWatchExpired) {
+			logger.Printf("Source %s: %v (retrying in %s)", s.Name(), err, backoff)
+		}
+
+		if !sleep(ctx, clock, backoff) {
+			return nil
+		}
+		backoff = min(backoff*2, 30*time.Second)
+	}
+	return nil
+}
+
+// watchFrom 从 resourceVersion 开始监听，直到连接关闭；connected 表示 watch 请求是否成功建立，
+// 服务端正常关闭连接时 err 为 nil
+func (s *KubernetesSource) watchFrom(ctx context.Context, resourceVersion string, notify func()) (connected bool, err error) {
+	q := url.Values{
+		"watch":           {"true"},
+		"fieldSelector":   {"metadata.name=" + s.Object},
+		"resourceVersion": {resourceVersion},
+	}
+	req, err := s.newRequest(ctx, "/api/v1/namespaces/"+s.namespace()+"/"+s.resource(), q)
+	if err != nil {
+		return false, err
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("watch %s: %s", s.Object, resp.Status)
+	}
+
+	dec := json.NewDecoder(resp.Body)
+	for {
+		var ev struct {
+			Type   string    `json:"type"`
+			Object k8sObject `json:"object"`
+		}
+		if err := dec.Decode(&ev); err != nil {
+			if errors.Is(err, io.EOF) {
+				return true, nil
+			}
+			return true, err
+		}
+		switch ev.Type {
+		case "ADDED", "MODIFIED", "DELETED":
+			s.seen(ev.Object.Metadata.ResourceVersion)
+			notify()
+		case "ERROR":
+			// 通常是 resourceVersion 过期，返回后重新 get 并继续监听
+			return true, errWatchExpired
+		}
+	}
+}
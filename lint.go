@@ -0,0 +1,99 @@
+package loadenv
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// LintIssue 一个疑似模板残留的值
+type LintIssue struct {
+	Key    string
+	Value  string // 敏感键（参见 IsSecretKey）的值为 Redacted
+	Reason string
+}
+
+func (i LintIssue) String() string {
+	return fmt.Sprintf("%s=%q: %s", i.Key, i.Value, i.Reason)
+}
+
+// placeholderWords 常见的占位值（不区分大小写）
+var placeholderWords = []string{
+	"changeme", "change-me", "change_me", "replaceme", "replace-me", "replace_me",
+	"todo", "fixme", "tbd", "xxx", "placeholder", "secret", "password",
+}
+
+var (
+	// unexpandedRe 未展开的变量引用，如 ${VAR}
+	unexpandedRe = regexp.MustCompile(`\$\{[A-Za-z_][A-Za-z0-9_.-]*\}`)
+	// angleRe 尖括号占位符，如 <your-token>
+	angleRe = regexp.MustCompile(`^<[^<>]+>$`)
+	// yourRe your-xxx-here 形式的占位符
+	yourRe = regexp.MustCompile(`(?i)^your[-_ ].*([-_ ]here)?$`)
+)
+
+// requiredSuffixes 键名以这些后缀结尾时，空值通常意味着漏填
+var requiredSuffixes = []string{"_URL", "_URI", "_HOST", "_ADDR", "_DSN", "_ENDPOINT"}
+
+// Lint 检查 env 中疑似模板残留的值：changeme、TODO 之类的占位值、未展开的 ${VAR}、
+// 尖括号占位符，以及看起来必填（敏感键或 _URL、_HOST 等后缀）却为空的键。结果按键名排序
+func Lint(env map[string]string) []LintIssue {
+	var issues []LintIssue
+	for key, value := range env {
+		if reason := lintValue(key, value); reason != "" {
+			shown := value
+			if IsSecretKey(key) {
+				shown = Redacted
+			}
+			issues = append(issues, LintIssue{Key: key, Value: shown, Reason: reason})
+		}
+	}
+	sort.Slice(issues, func(i, j int) bool { return issues[i].Key < issues[j].Key })
+	return issues
+}
+
+// lintValue 返回值被判定为占位符的原因，正常时返回空字符串
+func lintValue(key, value string) string {
+	v := strings.TrimSpace(value)
+	if v == "" {
+		if IsSecretKey(key) || hasRequiredSuffix(key) {
+			return "empty value for a key that looks required"
+		}
+		return ""
+	}
+	for _, w := range placeholderWords {
+		if strings.EqualFold(v, w) {
+			return "placeholder value"
+		}
+	}
+	switch {
+	case unexpandedRe.MatchString(v):
+		return "unexpanded variable reference"
+	case angleRe.MatchString(v):
+		return "angle-bracket placeholder"
+	case yourRe.MatchString(v):
+		return "placeholder value"
+	}
+	return ""
+}
+
+func hasRequiredSuffix(key string) bool {
+	k := strings.ToUpper(key)
+	for _, s := range requiredSuffixes {
+		if strings.HasSuffix(k, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// lint 按 Config.Lint 检查即将加载的变量并记录警告
+func (l *Loader) lint(env map[string]string) {
+	if !l.cfg.Lint {
+		return
+	}
+	for _, issue := range Lint(env) {
+		l.logger.Printf("Warning: %s", issue)
+	}
+}
@@ -2,212 +2,1006 @@
 package loadenv
 
 import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"io/fs"
 	"log"
+	"maps"
 	"os"
 	"path/filepath"
-	"strings"
+	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/fsnotify/fsnotify"
-	"github.com/joho/godotenv"
 )
 
 var (
 	once     sync.Once
-	watcher  *fsnotify.Watcher
-	closeCh  chan struct{}
-	filePath string
-	mu       sync.RWMutex
-	logger   *log.Logger
+	std      atomic.Pointer[Loader]
+	initDone = make(chan struct{}) // InitEnv 完成（无论成功与否）后关闭
+	initErr  error                 // InitEnv 的结果，initDone 关闭后只读
 )
 
 // Config 配置参数
 type Config struct {
-	FilePath    string        // 环境文件路径
-	HotReload   bool          // 是否启用热重载
-	Logger      *log.Logger   // 自定义日志记录器
-	ReloadDelay time.Duration // 重载延迟（防抖）
+	FilePath     string        // 环境文件路径
+	HotReload    bool          // 是否启用热重载
+	Logger       *log.Logger   // 自定义日志记录器
+	ReloadDelay  time.Duration // 重载延迟（防抖）
+	Clock        Clock         // 时间源，默认使用真实时间；来源的轮询、重试等待也按它计算
+	FS           FS            // 读取和监听环境文件、来源文件使用的文件系统，默认使用本地文件系统和 fsnotify；FileSource 的写入和 KubernetesSource 名称中默认命名空间的读取不经过它
+	ManualEvents bool          // 不创建文件监听器，改由 Notify 投递文件变更事件
+	WatchChmod   bool          // 文件属性或属主变化（Chmod 事件）也可能触发重载，仅在内容校验和变化时才真正重载
+	Isolated     bool          // 不读写进程环境变量，配置只保存在加载器的快照中
+	Lazy         bool          // New 不加载文件，首次读取配置（Get、Snapshot、Bind 等）时才加载并启动监听，参见 EnsureLoaded
+
+	// 真实环境优先：New 时进程中已存在的变量（如 Kubernetes 注入的变量）总是胜出，
+	// 不会被环境文件、来源、覆盖文件或派生值遮蔽，Isolated 和 LockSecrets 时也是如此；
+	// 默认只是不覆盖进程中已有的变量，而这一策略保证快照中的值也是它，并由 Source/Explain 报告。
+	// PreferOSEnv 作用于所有键，PreferOSEnvKeys 只作用于列出的键（以 * 结尾表示前缀）
+	PreferOSEnv     bool
+	PreferOSEnvKeys []string
+
+	// 加载
+	UnsetRemoved   bool              // 重载时删除从文件中移除的键（仅限由本加载器写入的键）
+	ScrubSecrets   bool              // 敏感键被删除时立即从进程环境变量中删除，且不在重载结果中保留其旧值
+	Formats        map[string]Format // 键的格式约束，不符合时加载失败（重载失败时保留原值）
+	Transformers   []Transformer     // 加载时按顺序作用于每个值的转换器，在格式校验之前执行
+	Sources        []Source          // 环境文件之外的配置来源，按顺序覆盖文件中的值
+	SourceTimeout  time.Duration     // 每次读取单个来源的超时，默认 30 秒；读取期间加载器的读写锁被占用
+	AgeIdentity    string            // age 身份文件路径，环境文件为 age 加密格式时用于解密
+	Parse          ParseOptions      // 值的空白、引号和末尾换行的处理方式，作用于环境文件、默认值文件和覆盖文件，默认与 ParseBytes 相同
+	Encoding       string            // 环境文件编码（如 windows-1252、gbk），默认 UTF-8；带 BOM 的文件自动识别
+	PodMetadata    bool              // 注入 POD_NAME、POD_NAMESPACE、NODE_NAME 等 Pod 元数据，文件和来源中的同名键优先
+	PodInfoDir     string            // Downward API 卷的挂载目录，默认 /etc/podinfo
+	DefaultsFile   string            // 默认值文件（如 .env.defaults），优先级低于环境文件；相对路径相对于环境文件所在目录，不存在时忽略
+	LockSecrets    bool              // 敏感键的值保存在 mlock 锁定、不会被换出的内存中，且不写入进程环境变量；不支持的平台退化为普通内存
+	SealSecrets    bool              // 快照中敏感键的值以进程级密钥加密保存，读取时解密，避免明文出现在内存转储中（Isolated 时效果最完整）
+	ManagedSecrets bool              // 凭据必须来自 Sources 或加密文件：明文环境文件、默认值文件或覆盖文件中出现疑似凭据（参见 ScanSecrets）时加载失败
+	Lint           bool              // 加载时对疑似模板残留的值（changeme、TODO、未展开的 ${VAR} 等）记录警告，参见 Lint
+	SchemaFile     string            // 模式文件（如 .env.example、.env.schema），其中的键必须都有非空值，否则加载失败并列出缺失的键
+	Annotations    bool              // 解析环境文件和 SchemaFile 中键上方的注释注解（# type: int  # required  # desc: ...）并在加载时校验，参见 KeyMeta
+	TraceLoad      bool              // 调试用：每次加载后逐键记录各层的候选值、胜出的一层及规则（输出量很大，敏感值隐藏），参见 Explain
+
+	// 模式迁移：环境文件的版本（VersionKey 的值，默认 CONFIG_VERSION）低于最新的迁移时，
+	// 加载时自动依次执行迁移，旧部署中的文件无需修改即可继续使用
+	Migrations []Migration
+	VersionKey string
+
+	// 弃用计划：移除日期之前读取弃用的键时记录警告，之后仍设置该键时加载失败；
+	// WarnExpiredDeprecations 为 true 时过期后也只记录警告
+	Deprecations            []Deprecation
+	WarnExpiredDeprecations bool
+
+	// 发布安全：保留历史版本用于回滚，金丝雀检查不通过时自动回滚
+	Canary          *Canary // 重载后的新配置需通过金丝雀检查，否则自动回滚；首次加载不检查
+	RetainSnapshots int     // 保留最近多少个不同版本的快照用于 RollbackTo 和 Pin，默认 10，小于 0 时不保留
+
+	// 准入策略：加载和重载应用之前对合并后的完整配置求值，任一策略不通过时拒绝该配置
+	Policies []Policy
+
+	// 人工编辑的值：BoolValues 为布尔值额外接受的写法（不区分大小写，如 HumanBoolValues），
+	// DigitSeparators 为整数中允许的数字分隔符（如 "_," 接受 1_000 和 1,000）；
+	// 作用于绑定的结构体、BindInt64Var、BindBoolVar 和注解中的类型，默认都不启用
+	BoolValues      map[string]bool
+	DigitSeparators string
+
+	// 绑定的结构体：ValidateStruct 在 Validate 方法之后额外执行校验，例如 go-playground/validator 的 validate.Struct；
+	// GeneratedFile 保存 defaultFn 标签生成的值（.env 格式，权限 0600），重启后复用，相对路径相对于环境文件所在目录
+	ValidateStruct func(v any) error
+	GeneratedFile  string
+
+	// 钩子
+	OnChangeExec []string                     // 重载生效后执行的外部命令（首项为程序，其余为参数），在后台依次执行，不阻塞重载
+	ExecTimeout  time.Duration                // OnChangeExec 的超时，默认 1 分钟，超时后结束子进程
+	Render       []RenderTarget               // 每次加载后重新渲染的模板文件
+	OnAccess     func(key string, found bool) // 每次通过 Get、Lookup 等读取单个键时调用，可用于审计
+	OnConflict   func(Conflict)               // 发现加载器写入的键被其他代码修改时调用
+	OnShadow     func([]Shadow)               // 加载时发现文件与进程原有变量的值不同（其中一方被忽略）时以全部这样的键调用一次
+
+	// 定时覆盖：在指定时段内叠加到环境文件之上的覆盖文件，进入和离开时段时自动重载（需要 HotReload）
+	Overlays []Overlay
+
+	// 分组：每个分组拥有独立的校验、变更回调和重载策略
+	Groups []Group
+
+	// 冲突检测：重载时总是检查；ConflictCheckInterval 大于 0 时还会按该间隔周期性检查
+	ConflictCheckInterval time.Duration
+
+	// 心跳：每隔 HeartbeatInterval（默认 1 分钟）报告一次当前运行的配置版本，
+	// 可以作为指标标签上报，在某个副本落后于预期的配置发布时告警
+	OnHeartbeat       func(Heartbeat)
+	HeartbeatInterval time.Duration
+
+	// 监听自检间隔，默认 30 秒：发现文件不再被监听（如被删除后重建）时重新添加并触发重载
+	WatchCheckInterval time.Duration
+}
+
+// Loader 环境变量加载器，每个实例拥有独立的文件、监听器和状态
+//
+// 并发约定：
+//   - 所有导出方法都可以被多个 goroutine 同时调用
+//   - cfg、absPath、logger 和 schedules 在 New 返回前确定，之后只读；watcher 只由监听协程使用
+//   - 重载（无论来自文件事件还是 Reload 调用）串行执行，变更比较、模板渲染和钩子不会交错
+//   - Close 之后不会再开始新的重载，已在执行的重载会正常结束
+type Loader struct {
+	cfg       Config
+	absPath   string // 环境文件的绝对路径，在 New 中解析一次，读取、监听、日志和钩子都使用它，之后的 os.Chdir 不影响
+	logger    *log.Logger
+	watcher   Watcher
+	closeCh   chan struct{}
+	closed    sync.Once
+	watchDone chan struct{} // 监听协程退出后关闭
+	schedules []schedule    // 与 cfg.Overlays 一一对应的生效时段，在 New 中解析
+	gen       *generator    // 绑定的结构体中 defaultFn 生成的值
+	values    *valueParser  // 布尔值和整数的解析选项，参见 Config.BoolValues
+
+	onAccess func(key string, found bool) // 快照的读取回调（含弃用警告），在 New 中确定
+	osEnv    map[string]string            // New 时的进程环境变量，仅在配置了真实环境优先策略时记录
+	warned   sync.Map                     // 已记录过弃用警告的键
+
+	mu      sync.RWMutex            // 保护 applied 及对进程环境变量的写入
+	applied map[string]string       // 由本加载器写入的键及写入的值，重载时允许覆盖
+	derived []*derivedValue         // 已注册的派生值，由 mu 保护
+	buf     []byte                  // 读取环境文件的缓冲区，在多次重载之间复用，由 mu 保护
+	fileSum [sha256.Size]byte       // 上一次读取的文件内容的校验和（仅 WatchChmod 时计算），由 mu 保护
+	modTime time.Time               // 上一次读取时文件的修改时间，由 mu 保护
+	last    map[string]string       // 上一次成功读取的文件与来源合并结果（分组策略处理之后），由 mu 保护
+	history []*Snapshot             // 保留的最近快照（从旧到新，校验和互不相同），参见 RollbackTo，由 mu 保护
+	pinned  string                  // Pin 固定的配置版本，由 mu 保护
+	defs    map[string][]Definition // 正在进行的加载中每个键的各层定义，加载完成后交给快照，参见 Explain，由 mu 保护
+	meta    map[string]KeyMeta      // 最近一次加载读到的注释注解，加载完成后交给快照，参见 Config.Annotations，由 mu 保护
+
+	snapshot  atomic.Pointer[Snapshot] // 最近一次加载的配置快照
+	started   atomic.Bool              // 已完成首次加载并启动监听（或已关闭），参见 Config.Lazy
+	startMu   sync.Mutex               // 串行化 Lazy 加载器的首次加载
+	frozen    atomic.Bool              // 冻结后不再重载
+	held      atomic.Bool              // Pin 期间不再重载，与 frozen 分开，Unpin 不解除 Freeze
+	reloading atomic.Bool              // 重载（包括渲染和钩子）进行中
+
+	reloadMu sync.Mutex // 串行化重载
+
+	execMu sync.Mutex     // 串行化 OnChangeExec，命令按重载顺序依次执行
+	execs  sync.WaitGroup // 尚未结束的 OnChangeExec，在持有 reloadMu 时增加
+
+	stats stats // 重载统计，参见 Stats
+
+	hooksMu    sync.Mutex // 保护 hooks、validators 和 dependents
+	hooks      []func(*Snapshot)
+	validators []func(*Snapshot) error // 绑定的结构体的解码和校验，参见 Bind
+	dependents []*dependent            // 通过 Bind 系列函数注册的组件，参见 DependencyGraph
+
+	subsMu sync.Mutex // 保护 subs
+	subs   []*Subscription
+
+	debounceMu sync.Mutex // 保护 timer 和 lastEvent
+	timer      Timer
+	lastEvent  time.Time
 }
 
 // InitEnv 初始化环境变量加载
 func InitEnv(cfg Config) error {
-	var initErr error
+	var err error
 	once.Do(func() {
-		// 设置默认值
-		if cfg.FilePath == "" {
-			cfg.FilePath = ".env"
+		var l *Loader
+		l, err = New(cfg)
+		std.Store(l)
+		initErr = err
+		close(initDone)
+	})
+	return err
+}
+
+// WaitUntilLoaded 阻塞直到默认加载器完成首次加载，返回 InitEnv 的错误；ctx 先结束时返回 ctx.Err()
+//
+// 用于 InitEnv 在另一个协程中异步执行（例如远端来源较慢）时，
+// 让依赖配置的组件在启动阶段等待，而不是读到空的配置：
+//
+//	go loadenv.InitEnv(cfg)
+//	...
+//	if err := loadenv.WaitUntilLoaded(ctx); err != nil { ... }
+//
+// Config.Lazy 时 InitEnv 不加载文件，这里只等待加载器创建，需要等待加载完成时使用 EnsureLoaded
+func WaitUntilLoaded(ctx context.Context) error {
+	select {
+	case <-initDone:
+		return initErr
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// New 创建并初始化一个独立的加载器
+func New(cfg Config) (*Loader, error) {
+	// 设置默认值
+	if cfg.FilePath == "" {
+		cfg.FilePath = ".env"
+	}
+	if cfg.ReloadDelay == 0 {
+		cfg.ReloadDelay = 2 * time.Second
+	}
+	if cfg.Logger == nil {
+		cfg.Logger = defaultLogger()
+	}
+	if cfg.Clock == nil {
+		cfg.Clock = realClock{}
+	}
+	if cfg.FS == nil {
+		cfg.FS = osFS{}
+	}
+	if cfg.HeartbeatInterval == 0 {
+		cfg.HeartbeatInterval = time.Minute
+	}
+	if cfg.Canary != nil {
+		c := *cfg.Canary
+		if c.Window == 0 {
+			c.Window = 30 * time.Second
 		}
-		if cfg.ReloadDelay == 0 {
-			cfg.ReloadDelay = 2 * time.Second
+		if c.Interval == 0 {
+			c.Interval = time.Second
 		}
-		if cfg.Logger == nil {
-			cfg.Logger = log.New(os.Stdout, "[ENV] ", log.LstdFlags)
+		cfg.Canary = &c
+	}
+	if cfg.RetainSnapshots == 0 {
+		cfg.RetainSnapshots = 10
+	}
+	if cfg.WatchCheckInterval == 0 {
+		cfg.WatchCheckInterval = 30 * time.Second
+	}
+	if cfg.SourceTimeout == 0 {
+		cfg.SourceTimeout = 30 * time.Second
+	}
+	if cfg.ExecTimeout == 0 {
+		cfg.ExecTimeout = time.Minute
+	}
+
+	absPath, err := filepath.Abs(cfg.FilePath)
+	if err != nil {
+		return nil, err
+	}
+
+	l := &Loader{
+		cfg:       cfg,
+		absPath:   absPath,
+		logger:    cfg.Logger,
+		applied:   make(map[string]string),
+		closeCh:   make(chan struct{}),
+		watchDone: make(chan struct{}),
+	}
+
+	if err := checkMigrations(cfg.Migrations); err != nil {
+		return nil, err
+	}
+	if l.values, err = newValueParser(cfg.BoolValues, cfg.DigitSeparators); err != nil {
+		return nil, err
+	}
+	l.onAccess = l.accessHook()
+	if cfg.PreferOSEnv || len(cfg.PreferOSEnvKeys) > 0 {
+		l.osEnv = environMap()
+	}
+	generated := ""
+	if cfg.GeneratedFile != "" {
+		generated = l.sidecar(cfg.GeneratedFile)
+	}
+	if l.gen, err = newGenerator(cfg.FS, generated); err != nil {
+		return nil, err
+	}
+	for _, o := range cfg.Overlays {
+		s, err := parseSchedule(o.Schedule, o.Location)
+		if err != nil {
+			return nil, err
 		}
+		l.schedules = append(l.schedules, s)
+	}
+
+	if cfg.Lazy {
+		return l, nil
+	}
+	f, err := l.start()
+	if err != nil {
+		l.report(f)
+		return nil, err
+	}
+	l.started.Store(true)
+	l.report(f)
+	return l, nil
+}
 
-		logger = cfg.Logger
-		filePath = cfg.FilePath
+// start 执行首次加载并启动监听器和定时任务，返回的问题由调用方在标记已启动之后报告，
+// 回调中读取配置时不会再次触发首次加载
+func (l *Loader) start() (findings, error) {
+	cfg := l.cfg
+	_, snap, f, err := l.loadFindings()
+	if err != nil {
+		return f, err
+	}
+	l.renderAll(snap)
 
-		// 首次加载
-		if err := load(); err != nil {
-			initErr = err
-			return
+	// 初始化监听器
+	if cfg.HotReload && !cfg.ManualEvents {
+		if err := l.initWatcher(); err != nil {
+			return f, err
+		}
+		go l.watchEvents()
+	} else {
+		close(l.watchDone)
+	}
+	if cfg.HotReload {
+		l.watchSources()
+		if len(cfg.Overlays) > 0 {
+			l.scheduleOverlays()
 		}
+	}
+	if cfg.ConflictCheckInterval > 0 && !cfg.Isolated {
+		l.scheduleConflictCheck()
+	}
+	if cfg.OnHeartbeat != nil {
+		l.scheduleHeartbeat()
+	}
+	return f, nil
+}
 
-		// 初始化监听器
-		if cfg.HotReload {
-			if err := initWatcher(cfg.FilePath); err != nil {
-				initErr = err
-				return
+// EnsureLoaded 立即执行 Lazy 加载器的首次加载，已加载时直接返回 nil
+//
+// 首次读取配置时会隐式调用它，但读取方法无法返回错误（失败时只记录日志，读取回退到进程环境变量，
+// 下一次读取时重试）；需要处理加载错误时显式调用。并发调用共享同一次加载
+func (l *Loader) EnsureLoaded() error {
+	if l.started.Load() {
+		return nil
+	}
+	l.startMu.Lock()
+	if l.started.Load() {
+		l.startMu.Unlock()
+		return nil
+	}
+	f, err := l.start()
+	if err == nil {
+		l.started.Store(true)
+	}
+	l.startMu.Unlock()
+	// 回调中可能读取配置，必须在释放 startMu 之后调用
+	l.report(f)
+	if err != nil {
+		l.logger.Printf("Lazy load failed: %v", err)
+		return err
+	}
+	return nil
+}
+
+// EnsureLoaded 对默认加载器执行 Loader.EnsureLoaded，未初始化时返回 nil
+func EnsureLoaded() error {
+	if l := std.Load(); l != nil {
+		return l.EnsureLoaded()
+	}
+	return nil
+}
+
+// defaultLogger 返回默认的日志记录器
+func defaultLogger() *log.Logger {
+	return log.New(os.Stdout, "[ENV] ", log.LstdFlags)
+}
+
+// load 实际加载环境变量的方法，只写入与上次加载相比发生变化的键
+//
+// 进程中已存在且不是由本加载器写入的变量不会被覆盖，
+// 由本加载器写入的变量在重载时会被更新为文件中的新值；
+// 开启 UnsetRemoved 时，从文件中删除的键也会从进程环境变量中删除。
+// 返回实际写入或删除的键（已排序；Isolated 模式下为值发生变化的键）以及本次加载生成的快照
+func (l *Loader) load() ([]string, *Snapshot, error) {
+	applied, snap, f, err := l.loadFindings()
+	// 冲突在释放锁之后报告，回调中可以安全地调用加载器的方法
+	l.report(f)
+	return applied, snap, err
+}
+
+// findings 加载中发现、需要在释放锁之后报告的问题，参见 Config.OnConflict 和 Config.OnShadow
+type findings struct {
+	conflicts []Conflict
+	shadows   []Shadow
+}
+
+// report 记录并通过回调报告加载中发现的问题，调用方不能持有 mu 或 startMu
+func (l *Loader) report(f findings) {
+	l.reportConflicts(f.conflicts)
+	l.reportShadows(f.shadows)
+}
+
+// loadFindings 执行 load，但不报告发现的问题而是返回给调用方
+func (l *Loader) loadFindings() ([]string, *Snapshot, findings, error) {
+	absPath := l.absPath
+	var f findings
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.logger.Printf("Loading environment from: %s", absPath)
+	env, origins, err := l.read(absPath)
+	if err != nil {
+		return nil, nil, f, err
+	}
+	if err := l.applyGroups(env, origins); err != nil {
+		return nil, nil, f, err
+	}
+	var last map[string]string
+	if len(l.cfg.Groups) > 0 {
+		last = maps.Clone(env)
+	}
+
+	var applied []string
+	prev := l.snapshot.Load()
+	snap := &Snapshot{
+		origins:  origins,
+		onAccess: l.onAccess,
+		isolated: l.cfg.Isolated,
+		loadedAt: l.cfg.Clock.Now(),
+		values:   l.values,
+	}
+	if l.cfg.Isolated {
+		snap.env = env
+	} else {
+		f.conflicts = l.conflicts()
+		if applied, err = l.apply(env); err != nil {
+			return applied, nil, f, err
+		}
+		// 文件中每个键的实际生效值（进程中原有的变量优先）
+		snap.env = make(map[string]string, len(env))
+		for key, value := range env {
+			if l.lockedKey(key) {
+				if v, ok := os.LookupEnv(key); ok {
+					snap.env[key] = v
+					origins[key] = SourceOS
+					l.define(key, SourceOS, v)
+				} else {
+					snap.env[key] = value
+				}
+				continue
+			}
+			if v, ok := os.LookupEnv(key); ok {
+				snap.env[key] = v
+				if _, owned := l.applied[key]; !owned && v != value {
+					origins[key] = SourceOS
+					l.define(key, SourceOS, v)
+				}
 			}
-			go watchEvents(cfg.ReloadDelay)
 		}
-	})
-	return initErr
+	}
+	// Isolated 模式下 snap.env 与 env 是同一个映射，必须在派生之前比较
+	f.shadows = l.shadows(env, origins)
+	l.derive(prev, snap)
+	l.preferOS(snap)
+	snap.defs = l.defs
+	snap.meta = l.meta
+	if l.cfg.Isolated {
+		for _, c := range diffEnv(prev.Map(), snap.env) {
+			applied = append(applied, c.Key)
+		}
+	}
+	snap.checksum = HashEnv(snap.env)
+	if err := l.seal(snap); err != nil {
+		return applied, nil, f, err
+	}
+	l.lockSecrets(snap)
+	l.store(snap)
+	l.last = last
+	if l.cfg.TraceLoad {
+		l.trace(snap)
+	}
+
+	sort.Strings(applied)
+	return applied, snap, f, nil
 }
 
-// load 实际加载环境变量的方法
-func load() error {
-	absPath, err := filepath.Abs(filePath)
+// read 读取并解析环境文件，合并其他来源，执行转换和校验，返回最终的键值对及每个键的来源，调用方需持有 mu
+func (l *Loader) read(absPath string) (env, origins map[string]string, err error) {
+	data, err := l.readFile(absPath)
 	if err != nil {
-		return err
+		return nil, nil, err
+	}
+	encrypted := isEncrypted(data)
+	if encrypted {
+		if l.cfg.AgeIdentity == "" {
+			return nil, nil, ErrNoIdentity
+		}
+		if data, err = decrypt(l.cfg.FS, data, l.cfg.AgeIdentity); err != nil {
+			return nil, nil, err
+		}
+	}
+	if data, err = decode(data, l.cfg.Encoding); err != nil {
+		return nil, nil, err
+	}
+	var fileMeta map[string]KeyMeta
+	if l.cfg.Annotations {
+		env, fileMeta, err = parseMeta(data, os.LookupEnv, l.cfg.Parse)
+	} else {
+		env, err = parse(data, os.LookupEnv, l.cfg.Parse)
+	}
+	if l.cfg.SealSecrets {
+		// 不在缓冲区中保留文件的明文
+		clear(data)
+		clear(l.buf)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	// 加密的环境文件不算明文
+	if !encrypted {
+		if err := l.checkPlaintext(absPath, env); err != nil {
+			return nil, nil, err
+		}
+	}
+	if err := l.migrate(env); err != nil {
+		return nil, nil, err
+	}
+
+	defaults, defaultsPath, err := l.readDefaults()
+	if err != nil {
+		return nil, nil, err
+	}
+	l.defs = make(map[string][]Definition, len(env))
+	origins = make(map[string]string, len(env))
+	for key, value := range defaults {
+		l.define(key, "file:"+defaultsPath, value)
+		if _, ok := env[key]; !ok {
+			origins[key] = "file:" + defaultsPath
+		}
+	}
+	for key, value := range env {
+		l.define(key, "file:"+absPath, value)
+		origins[key] = "file:" + absPath
+	}
+	if defaults != nil {
+		env = Merge(defaults, env)
+	}
+	if env, err = l.readOverlays(env, origins); err != nil {
+		return nil, nil, err
+	}
+	if env, err = l.loadSources(env, origins); err != nil {
+		return nil, nil, err
 	}
+	if l.cfg.PodMetadata {
+		pod := podMetadata(l.cfg.FS, l.cfg.PodInfoDir)
+		for key, value := range pod {
+			l.defs[key] = append([]Definition{{Source: SourcePod, Value: shown(key, value)}}, l.defs[key]...)
+			if _, ok := env[key]; !ok {
+				origins[key] = SourcePod
+			}
+		}
+		env = Merge(pod, env)
+	}
+	var raw map[string]string
+	if len(l.cfg.Transformers) > 0 {
+		raw = maps.Clone(env)
+	}
+	if err := transform(l.cfg.Transformers, env); err != nil {
+		return nil, nil, err
+	}
+	for key, value := range raw {
+		if env[key] != value {
+			l.define(key, SourceTransform, env[key])
+		}
+	}
+	if l.cfg.Annotations {
+		if err := l.readMeta(fileMeta, absPath); err != nil {
+			return nil, nil, err
+		}
+	}
+	l.lint(env)
+	// 完成所有校验后一次报告全部问题，错误中补充注解里的键说明
+	errs := []error{l.checkSchema(env)}
+	if l.cfg.Annotations {
+		errs = append(errs, l.checkMeta(l.meta, env))
+	}
+	errs = append(errs,
+		l.checkDeprecations(env),
+		checkFormats(l.cfg.Formats, env),
+		admit(l.cfg.Policies, env),
+		l.checkBindings(env),
+	)
+	if err := joinErrors(errs...); err != nil {
+		return nil, nil, describe(l.meta, err)
+	}
+	return env, origins, nil
+}
 
-	mu.Lock()
-	defer mu.Unlock()
+// readFile 将文件读入可复用的缓冲区，返回的切片在下一次调用前有效，调用方需持有 mu
+//
+// 频繁重载大文件时避免每次分配新的缓冲区
+func (l *Loader) readFile(path string) ([]byte, error) {
+	f, err := l.cfg.FS.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
 
-	logger.Printf("Loading environment from: %s", absPath)
-	return godotenv.Load(absPath)
+	if fi, err := f.Stat(); err == nil {
+		l.modTime = fi.ModTime()
+		if int(fi.Size())+bytes.MinRead > cap(l.buf) {
+			l.buf = make([]byte, 0, int(fi.Size())+bytes.MinRead)
+		}
+	}
+	buf := bytes.NewBuffer(l.buf[:0])
+	if _, err := buf.ReadFrom(f); err != nil {
+		return nil, err
+	}
+	l.buf = buf.Bytes()
+	if l.cfg.WatchChmod {
+		l.fileSum = sha256.Sum256(l.buf)
+	}
+	return l.buf, nil
 }
 
-// initWatcher 初始化文件监听
-func initWatcher(path string) error {
-	var err error
-	watcher, err = fsnotify.NewWatcher()
+// contentChanged 判断磁盘上的文件内容是否与上一次读取时不同，读取失败时视为已变化
+func (l *Loader) contentChanged() bool {
+	data, err := fs.ReadFile(l.cfg.FS, l.absPath)
 	if err != nil {
-		return err
+		return true
+	}
+	sum := sha256.Sum256(data)
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return sum != l.fileSum
+}
+
+// candidate 返回 env 生效后将得到的快照：与 load 一样处理进程中原有变量的优先级、派生值和真实环境优先策略，
+// 但不写入进程环境变量，也不更新派生值缓存和定义记录，用于在配置生效之前校验它；调用方需持有 mu
+func (l *Loader) candidate(env map[string]string) *Snapshot {
+	snap := &Snapshot{
+		env:      make(map[string]string, len(env)),
+		origins:  make(map[string]string),
+		isolated: l.cfg.Isolated,
+		meta:     l.meta,
+		values:   l.values,
+	}
+	for key, value := range env {
+		if !l.cfg.Isolated {
+			// apply 不会覆盖不是由加载器写入的变量
+			if v, ok := os.LookupEnv(key); ok {
+				if _, owned := l.applied[key]; !owned {
+					value = v
+				}
+			}
+		}
+		snap.env[key] = value
+	}
+	l.computeDerived(l.snapshot.Load(), snap, false)
+	for key := range snap.env {
+		if l.prefersOS(key) {
+			snap.env[key] = l.osEnv[key]
+		}
+	}
+	return snap
+}
+
+// apply 将 env 写入进程环境变量，返回实际写入或删除的键，调用方需持有 mu
+func (l *Loader) apply(env map[string]string) ([]string, error) {
+	var applied []string
+	for key, value := range env {
+		if l.lockedKey(key) || l.prefersOS(key) {
+			continue
+		}
+		prev, owned := l.applied[key]
+		if owned && prev == value {
+			continue
+		}
+		if _, exists := os.LookupEnv(key); exists && !owned {
+			continue
+		}
+		if err := os.Setenv(key, value); err != nil {
+			return applied, err
+		}
+		l.applied[key] = value
+		applied = append(applied, key)
 	}
 
-	absPath, err := filepath.Abs(path)
+	for key := range l.applied {
+		if _, ok := env[key]; ok {
+			continue
+		}
+		if !l.cfg.UnsetRemoved && !(l.cfg.ScrubSecrets && IsSecretKey(key)) {
+			continue
+		}
+		if err := os.Unsetenv(key); err != nil {
+			return applied, err
+		}
+		delete(l.applied, key)
+		applied = append(applied, key)
+	}
+	return applied, nil
+}
+
+// ReloadResult 一次重载的结果
+//
+// 结果中的切片为每次重载新分配的副本，调用方可以自由持有或修改，不会影响加载器内部状态；
+// 开启 ScrubSecrets 时，敏感键（参见 IsSecretKey）的旧值不会出现在 Changes 中
+type ReloadResult struct {
+	Changes []Change   // 配置的变化，由重载前后的两个快照比较得出
+	Applied []string   // 实际写入或删除的进程环境变量
+	Meta    ReloadMeta // 触发原因和耗时，用于追踪缓慢或丢失的重载
+}
+
+// ReloadMeta 一次重载的因果信息
+type ReloadMeta struct {
+	Op         fsnotify.Op   // 触发重载的文件事件；通过 Reload 手动触发时为 0
+	ModTime    time.Time     // 读取时环境文件的修改时间
+	Checksum   string        // 重载后快照的校验和，参见 Snapshot.Checksum
+	DetectedAt time.Time     // 收到触发事件（防抖窗口内的第一个事件）或调用 Reload 的时间
+	AppliedAt  time.Time     // 新配置生效的时间
+	Latency    time.Duration // 从 DetectedAt 到 AppliedAt 的耗时，包括防抖延迟
+}
+
+// Reload 立即重新加载环境文件，并输出变更、渲染模板和执行钩子
+func (l *Loader) Reload() (ReloadResult, error) {
+	if err := l.EnsureLoaded(); err != nil {
+		return ReloadResult{}, err
+	}
+	l.reloadMu.Lock()
+	defer l.reloadMu.Unlock()
+	return l.reload(0, l.cfg.Clock.Now())
+}
+
+// reload 执行一次重载，op 和 detectedAt 描述触发原因，调用方需持有 reloadMu
+func (l *Loader) reload(op fsnotify.Op, detectedAt time.Time) (ReloadResult, error) {
+	var result ReloadResult
+	if l.Frozen() {
+		return result, ErrFrozen
+	}
+	l.reloading.Store(true)
+	defer l.reloading.Store(false)
+
+	before := l.Snapshot()
+	start := l.cfg.Clock.Now()
+	applied, snap, err := l.load()
+	end := l.cfg.Clock.Now()
+	l.stats.recordReload(start, end, err)
+	result.Applied = applied
 	if err != nil {
-		return err
+		l.logger.Printf("Reload failed: %v", err)
+		return result, err
 	}
+	l.logger.Printf("Successfully reloaded environment file %s (%d keys applied)", l.absPath, len(applied))
 
-	if err := watcher.Add(absPath); err != nil {
-		return err
+	l.mu.RLock()
+	modTime := l.modTime
+	l.mu.RUnlock()
+	result.Meta = ReloadMeta{
+		Op:         op,
+		ModTime:    modTime,
+		Checksum:   snap.Checksum(),
+		DetectedAt: detectedAt,
+		AppliedAt:  end,
+		Latency:    end.Sub(detectedAt),
 	}
 
-	closeCh = make(chan struct{})
-	logger.Printf("Starting hot reload watcher for: %s", absPath)
-	return nil
+	result.Changes = l.finish(before, snap, result.Meta)
+
+	if l.cfg.Canary != nil && len(result.Changes) > 0 {
+		// 检查期间新配置已经生效，不再视为重载中，检查请求不会被 Middleware 拒绝
+		l.reloading.Store(false)
+		if err := l.canary(snap); err != nil {
+			return result, l.rollbackCanary(before, snap, result.Meta, err)
+		}
+	}
+	return result, nil
 }
 
-func watchEvents(delay time.Duration) {
-	defer watcher.Close()
+// finish 在新快照生效后记录变化并执行渲染、钩子、分组回调、订阅通知和外部命令，返回相对 before 的变化
+//
+// 变更、日志、渲染和回调都以新快照为准，不再重新读取文件
+func (l *Loader) finish(before, snap *Snapshot, meta ReloadMeta) []Change {
+	changes := diffEnv(before.Map(), snap.Map())
+	for _, c := range changes {
+		// 日志中不输出敏感键的值
+		oldValue, newValue := c.Old, c.New
+		if IsSecretKey(c.Key) {
+			oldValue, newValue = Redacted, Redacted
+		}
+		switch c.Type {
+		case Added:
+			l.logger.Printf("New environment variable: %s = %s", c.Key, newValue)
+		case Modified:
+			l.logger.Printf("Environment variable changed: %s = %s (old value: %s)", c.Key, newValue, oldValue)
+		case Removed:
+			l.logger.Printf("Environment variable removed: %s", c.Key)
+		}
+	}
+	if l.cfg.ScrubSecrets {
+		for i := range changes {
+			if IsSecretKey(changes[i].Key) {
+				changes[i].Old = ""
+			}
+		}
+	}
 
-	var (
-		timer     *time.Timer
-		lastEvent time.Time
-	)
+	l.renderAll(snap)
+	l.runHooks(snap)
+	l.notifyGroups(changes)
 
-	// 读取当前 .env 文件内容
-	oldEnvContent, err := os.ReadFile(".env")
+	if len(changes) > 0 {
+		l.publish(Event{Snapshot: snap, Changes: changes, Meta: meta})
+	}
+
+	if len(l.cfg.OnChangeExec) > 0 && len(changes) > 0 {
+		l.execs.Add(1)
+		go func() {
+			defer l.execs.Done()
+			l.runChangeExec(changes)
+		}()
+	}
+	return changes
+}
+
+// initWatcher 初始化文件监听
+func (l *Loader) initWatcher() error {
+	var err error
+	l.watcher, err = l.cfg.FS.Watch()
 	if err != nil {
-		logger.Printf("Failed to read .env file: %v", err)
-		return
+		return err
 	}
-	oldEnv := parseEnvFile(string(oldEnvContent))
+
+	absPath := l.absPath
+
+	if err := l.watcher.Add(absPath); err != nil {
+		return err
+	}
+
+	l.logger.Printf("Starting hot reload watcher for: %s", absPath)
+	return nil
+}
+
+// watchEvents 处理文件事件，监听器失效时自动重建，直到加载器关闭
+func (l *Loader) watchEvents() {
+	defer close(l.watchDone)
+	defer func() { l.watcher.Close() }()
+
+	check := make(chan struct{}, 1)
+	l.scheduleWatchCheck(check)
 
 	for {
 		select {
-		case event, ok := <-watcher.Events:
+		case event, ok := <-l.watcher.Events():
 			if !ok {
-				return
+				if !l.restartWatcher() {
+					return
+				}
+				continue
 			}
+			l.handleEvent(event)
 
-			// 防抖处理
-			now := time.Now()
-			if now.Sub(lastEvent) < delay {
+		case err, ok := <-l.watcher.Errors():
+			if !ok {
+				if !l.restartWatcher() {
+					return
+				}
 				continue
 			}
+			l.stats.recordWatcherError(err)
+			l.logger.Printf("Watcher error: %v", err)
 
-			if event.Has(fsnotify.Write) || event.Has(fsnotify.Create) {
-				if timer != nil {
-					timer.Stop()
-				}
+		case <-check:
+			l.checkWatch()
+			l.scheduleWatchCheck(check)
 
-				timer = time.AfterFunc(delay, func() {
-					if err := load(); err != nil {
-						logger.Printf("Reload failed: %v", err)
-					} else {
-						logger.Printf("Successfully reloaded environment file")
-
-						// 读取新的 .env 文件内容
-						newEnvContent, err := os.ReadFile(".env")
-						if err != nil {
-							logger.Printf("Failed to read updated .env file: %v", err)
-							return
-						}
-						newEnv := parseEnvFile(string(newEnvContent))
-
-						// 比较并输出变化的环境变量
-						for key, newValue := range newEnv {
-							oldValue, exists := oldEnv[key]
-							if !exists {
-								logger.Printf("New environment variable: %s = %s", key, newValue)
-							} else if oldValue != newValue {
-								logger.Printf("Environment variable changed: %s = %s (old value: %s)", key, newValue, oldValue)
-							}
-						}
-
-						for key := range oldEnv {
-							if _, exists := newEnv[key]; !exists {
-								logger.Printf("Environment variable removed: %s", key)
-							}
-						}
-
-						// 更新 oldEnv 为新的环境变量
-						oldEnv = newEnv
-					}
-				})
-
-				lastEvent = now
-			}
+		case <-l.closeCh:
+			return
+		}
+	}
+}
 
-		case err, ok := <-watcher.Errors:
-			if !ok {
+// handleEvent 处理单个文件事件，在防抖延迟后触发重载
+func (l *Loader) handleEvent(event fsnotify.Event) {
+	l.debounceMu.Lock()
+	defer l.debounceMu.Unlock()
+
+	// 防抖处理
+	now := l.cfg.Clock.Now()
+	if now.Sub(l.lastEvent) < l.cfg.ReloadDelay {
+		return
+	}
+
+	written := event.Has(fsnotify.Write) || event.Has(fsnotify.Create)
+	if written || (l.cfg.WatchChmod && event.Has(fsnotify.Chmod)) {
+		if l.timer != nil {
+			l.timer.Stop()
+		}
+
+		l.timer = l.cfg.Clock.AfterFunc(l.cfg.ReloadDelay, func() {
+			l.reloadMu.Lock()
+			defer l.reloadMu.Unlock()
+			// 在持有锁之后再检查，保证 Shutdown 返回后不会开始新的重载
+			if l.isClosed() {
 				return
 			}
-			logger.Printf("Watcher error: %v", err)
+			// 只有属性变化时，用校验和确认内容确实变化再重载
+			if !written && !l.contentChanged() {
+				l.logger.Printf("Attributes of %s changed, content unchanged; skipping reload", event.Name)
+				return
+			}
+			l.reload(event.Op, now)
+		})
 
-		case <-closeCh:
-			return
-		}
+		l.lastEvent = now
 	}
 }
 
-// 解析 .env 文件内容
-func parseEnvFile(content string) map[string]string {
-	env := make(map[string]string)
-	lines := strings.Split(content, "\n")
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" || strings.HasPrefix(line, "#") {
-			continue
+// Notify 投递一次文件写入事件，与真实的文件变更走相同的防抖和重载流程
+//
+// 通常与 ManualEvents 和自定义 Clock 一起在测试中使用；未启用热重载或已关闭时忽略
+func (l *Loader) Notify() {
+	if !l.cfg.HotReload || l.isClosed() || l.Frozen() {
+		return
+	}
+	l.handleEvent(fsnotify.Event{Name: l.absPath, Op: fsnotify.Write})
+}
+
+// Close 停止热重载监听，并取消尚未触发的重载
+func (l *Loader) Close() {
+	l.closed.Do(func() {
+		close(l.closeCh)
+
+		// 从未加载过的 Lazy 加载器不再启动
+		l.startMu.Lock()
+		if !l.started.Load() {
+			close(l.watchDone)
+			l.started.Store(true)
 		}
-		parts := strings.SplitN(line, "=", 2)
-		if len(parts) == 2 {
-			key := strings.TrimSpace(parts[0])
-			value := strings.TrimSpace(parts[1])
-			env[key] = value
+		l.startMu.Unlock()
+
+		l.debounceMu.Lock()
+		if l.timer != nil {
+			l.timer.Stop()
 		}
+		l.debounceMu.Unlock()
+
+		l.closeSubs()
+	})
+}
+
+// Shutdown 优雅关闭：停止接收新的文件事件，等待正在执行的重载（包括渲染、钩子和 OnChangeExec）完成，
+// 再关闭文件监听器；ctx 到期时立即返回 ctx.Err()
+func (l *Loader) Shutdown(ctx context.Context) error {
+	l.Close()
+
+	done := make(chan struct{})
+	go func() {
+		l.reloadMu.Lock()
+		l.execs.Wait()
+		l.reloadMu.Unlock()
+		<-l.watchDone
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		l.logger.Printf("Loader shut down")
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// isClosed 判断加载器是否已关闭
+func (l *Loader) isClosed() bool {
+	select {
+	case <-l.closeCh:
+		return true
+	default:
+		return false
 	}
-	return env
 }
 
-// Close 停止热重载监听
+// Close 停止默认加载器的热重载监听
 func Close() {
-	if closeCh != nil {
-		close(closeCh)
+	if l := std.Load(); l != nil {
+		l.Close()
 	}
 }
+
+// Shutdown 优雅关闭默认加载器，参见 Loader.Shutdown
+func Shutdown(ctx context.Context) error {
+	if l := std.Load(); l != nil {
+		return l.Shutdown(ctx)
+	}
+	return nil
+}
@@ -0,0 +1,291 @@
+package loadenv
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestConcurrentReload 重载与快照读取、订阅（三种策略）和 Close 并发执行，配合 go test -race 检查数据竞争；
+// 每个快照和事件中 RACE_B 都必须与同一次加载的 RACE_A 一致
+func TestConcurrentReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+	write := func(i int) {
+		data := fmt.Sprintf("RACE_A=%d\nRACE_B=${RACE_A}-b\nRACE_%d=x\n", i, i%3)
+		if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+			t.Error(err)
+		}
+	}
+	write(0)
+	l, err := New(Config{FilePath: path, Logger: log.New(io.Discard, "", 0)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		for _, key := range []string{"RACE_A", "RACE_B", "RACE_0", "RACE_1", "RACE_2"} {
+			os.Unsetenv(key)
+		}
+	})
+	check := func(snap *Snapshot) {
+		if a, b := snap.Get("RACE_A"), snap.Get("RACE_B"); b != a+"-b" {
+			t.Errorf("RACE_A=%q but RACE_B=%q", a, b)
+		}
+	}
+
+	const reloads = 200
+	var (
+		wg      sync.WaitGroup
+		halfway = make(chan struct{})
+		stop    = make(chan struct{})
+	)
+	run := func(f func()) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			f()
+		}()
+	}
+
+	for _, policy := range []Backpressure{Block, DropOldest, Coalesce} {
+		sub := l.Subscribe(1, policy)
+		run(func() {
+			for ev := range sub.C {
+				check(ev.Snapshot)
+			}
+		})
+	}
+	run(func() {
+		for i := 1; i <= reloads; i++ {
+			write(i)
+			l.Reload()
+			if i == reloads/2 {
+				close(halfway)
+			}
+		}
+		close(stop)
+	})
+	for i := 0; i < 4; i++ {
+		run(func() {
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				check(l.Snapshot())
+				l.Get("RACE_A")
+				l.Lookup("RACE_1")
+			}
+		})
+	}
+	run(func() {
+		policies := []Backpressure{Block, DropOldest, Coalesce}
+		for i := 0; ; i++ {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			sub := l.Subscribe(1, policies[i%len(policies)])
+			select {
+			case ev, ok := <-sub.C:
+				if ok {
+					check(ev.Snapshot)
+				}
+			default:
+			}
+			sub.Close()
+		}
+	})
+	run(func() {
+		<-halfway
+		l.Close()
+	})
+	wg.Wait()
+}
+
+// TestLazyLoadCallbackReads Lazy 加载器首次加载时，OnShadow 回调中读取配置不会死锁，并读到本次加载的值
+func TestLazyLoadCallbackReads(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(path, []byte("LAZY_SHADOWED=file\nLAZY_OTHER=1\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("LAZY_SHADOWED", "process")
+	t.Cleanup(func() { os.Unsetenv("LAZY_OTHER") })
+
+	var (
+		l   *Loader
+		got = make(chan string, 1)
+	)
+	l, err := New(Config{
+		FilePath: path,
+		Lazy:     true,
+		Logger:   log.New(io.Discard, "", 0),
+		OnShadow: func([]Shadow) { got <- l.Get("LAZY_OTHER") },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 死锁时 startMu 一直被持有，Close 也会阻塞，因此只在加载完成后关闭
+	done := make(chan error, 1)
+	go func() { done <- l.EnsureLoaded() }()
+	select {
+	case err := <-done:
+		l.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("EnsureLoaded deadlocked in OnShadow")
+	}
+	select {
+	case v := <-got:
+		if v != "1" {
+			t.Fatalf("Get in OnShadow returned %q, want 1", v)
+		}
+	default:
+		t.Fatal("OnShadow was not called")
+	}
+}
+
+// recordFS 记录通过它打开的文件
+type recordFS struct {
+	FS
+	mu     sync.Mutex
+	opened map[string]bool
+}
+
+func (r *recordFS) Open(name string) (fs.File, error) {
+	r.mu.Lock()
+	r.opened[name] = true
+	r.mu.Unlock()
+	return r.FS.Open(name)
+}
+
+// TestSourcesUseConfigFS 来源由加载器调用时通过 Config.FS 读取文件
+func TestSourcesUseConfigFS(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, ".env")
+	if err := os.WriteFile(path, []byte("FS_FILE=1\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	envdir := filepath.Join(dir, "envdir")
+	if err := os.Mkdir(envdir, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(envdir, "FS_ENVDIR"), []byte("2\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	shared := filepath.Join(dir, "shared.env")
+	if err := os.WriteFile(shared, []byte("FS_SHARED=3\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	fsys := &recordFS{FS: OSFS(), opened: make(map[string]bool)}
+	l, err := New(Config{
+		FilePath: path,
+		FS:       fsys,
+		Isolated: true,
+		Logger:   log.New(io.Discard, "", 0),
+		Sources:  []Source{&EnvDirSource{Dir: envdir}, &FileSource{Path: shared}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	if got := l.Get("FS_ENVDIR") + l.Get("FS_SHARED"); got != "23" {
+		t.Fatalf("got %q, want 23", got)
+	}
+	for _, name := range []string{filepath.Join(envdir, "FS_ENVDIR"), shared} {
+		if !fsys.opened[name] {
+			t.Errorf("%s was not read through Config.FS", name)
+		}
+	}
+}
+
+// newBenchLoader 创建读取 n 个键的加载器，不输出日志
+func newBenchLoader(b *testing.B, prefix string, n int, isolated bool) *Loader {
+	b.Helper()
+	path := filepath.Join(b.TempDir(), ".env")
+	if err := os.WriteFile(path, benchmarkEnv(prefix, n), 0o600); err != nil {
+		b.Fatal(err)
+	}
+	l, err := New(Config{FilePath: path, Isolated: isolated, Logger: log.New(io.Discard, "", 0)})
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.Cleanup(func() {
+		l.Close()
+		for key := range l.Snapshot().Map() {
+			os.Unsetenv(key)
+		}
+	})
+	return l
+}
+
+func BenchmarkDiffEnv(b *testing.B) {
+	oldEnv, err := ParseBytes(benchmarkEnv("BENCH", 1000))
+	if err != nil {
+		b.Fatal(err)
+	}
+	newEnv := make(map[string]string, len(oldEnv))
+	for k, v := range oldEnv {
+		newEnv[k] = v
+	}
+	for i := 0; i < 10; i++ {
+		newEnv["BENCH_"+strconv.Itoa(i*100)] = "changed"
+		newEnv["BENCH_NEW_"+strconv.Itoa(i)] = "added"
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		diffEnv(oldEnv, newEnv)
+	}
+}
+
+func BenchmarkLoad(b *testing.B) {
+	for _, isolated := range []bool{false, true} {
+		name := "process"
+		if isolated {
+			name = "isolated"
+		}
+		b.Run(name, func(b *testing.B) {
+			l := newBenchLoader(b, "BENCH_LOAD_"+name, 1000, isolated)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, _, err := l.load(); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkApply(b *testing.B) {
+	l := newBenchLoader(b, "BENCH_APPLY", 1000, false)
+	// 两份配置交替写入，每次都有 1000 个键发生变化
+	envs := [2]map[string]string{l.Snapshot().Map(), make(map[string]string)}
+	for k, v := range envs[0] {
+		envs[1][k] = v + "-next"
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		l.mu.Lock()
+		_, err := l.apply(envs[i%2])
+		l.mu.Unlock()
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
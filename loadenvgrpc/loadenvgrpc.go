@@ -0,0 +1,61 @@
+// Package loadenvgrpc 提供 gRPC 服务端拦截器：为每次调用固定一个配置快照，并在响应头中携带配置版本
+//
+//	srv := grpc.NewServer(
+//		grpc.UnaryInterceptor(loadenvgrpc.UnaryServerInterceptor(l)),
+//		grpc.StreamInterceptor(loadenvgrpc.StreamServerInterceptor(l)),
+//	)
+//
+// 处理函数通过 loadenv.FromContext(ctx) 读取本次调用的快照，调用期间发生的重载不影响已在处理中的请求
+package loadenvgrpc
+
+import (
+	"context"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/solorez/loadenv"
+)
+
+// VersionKey 携带配置版本的响应头（gRPC 元数据键为小写）
+var VersionKey = strings.ToLower(loadenv.VersionHeader)
+
+// snapshot 返回 l 的当前快照，l 为 nil 时使用默认加载器
+func snapshot(l *loadenv.Loader) *loadenv.Snapshot {
+	if l == nil {
+		return loadenv.GetSnapshot()
+	}
+	return l.Snapshot()
+}
+
+// UnaryServerInterceptor 返回一元调用拦截器，l 为 nil 时使用默认加载器
+func UnaryServerInterceptor(l *loadenv.Loader) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		snap := snapshot(l)
+		ctx = loadenv.NewContext(ctx, snap)
+		// 设置失败（如调用方已发送响应头）不影响调用本身
+		_ = grpc.SetHeader(ctx, metadata.Pairs(VersionKey, snap.ShortChecksum()))
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor 返回流式调用拦截器，整个流使用同一个快照；l 为 nil 时使用默认加载器
+func StreamServerInterceptor(l *loadenv.Loader) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		snap := snapshot(l)
+		_ = ss.SetHeader(metadata.Pairs(VersionKey, snap.ShortChecksum()))
+		return handler(srv, &serverStream{
+			ServerStream: ss,
+			ctx:          loadenv.NewContext(ss.Context(), snap),
+		})
+	}
+}
+
+// serverStream 替换 Context 以携带快照
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context { return s.ctx }
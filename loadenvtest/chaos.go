@@ -0,0 +1,218 @@
+package loadenvtest
+
+import (
+	"bytes"
+	"io"
+	"io/fs"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/solorez/loadenv"
+)
+
+// ChaosFS 包装一个 loadenv.FS，按需注入读取和监听故障，用于验证应用对重载失败的处理
+//
+// 注入的故障只作用于下一次读取（或当前所有监听器），之后恢复正常：
+//
+//	fs := loadenvtest.NewChaosFS(loadenv.OSFS())
+//	l, _ := loadenv.New(loadenv.Config{FS: fs, HotReload: true})
+//	fs.CorruptNextRead()
+//	_, err := l.Reload() // 解析失败，原配置保持不变
+type ChaosFS struct {
+	base loadenv.FS
+
+	mu       sync.Mutex
+	readErr  error
+	corrupt  bool
+	truncate int // 大于等于 0 时下一次读取只返回前 truncate 个字节
+	watchers []*chaosWatcher
+}
+
+var _ loadenv.FS = (*ChaosFS)(nil)
+
+// NewChaosFS 返回包装 base 的 ChaosFS
+func NewChaosFS(base loadenv.FS) *ChaosFS {
+	return &ChaosFS{base: base, truncate: -1}
+}
+
+// FailNextRead 让下一次打开文件返回 err，模拟文件暂时不可读
+func (c *ChaosFS) FailNextRead(err error) {
+	c.mu.Lock()
+	c.readErr = err
+	c.mu.Unlock()
+}
+
+// CorruptNextRead 让下一次读取返回无法解析的内容，模拟解析失败
+func (c *ChaosFS) CorruptNextRead() {
+	c.mu.Lock()
+	c.corrupt = true
+	c.mu.Unlock()
+}
+
+// TruncateNextRead 让下一次读取只返回前 n 个字节，模拟读到写了一半的文件
+func (c *ChaosFS) TruncateNextRead(n int) {
+	c.mu.Lock()
+	c.truncate = n
+	c.mu.Unlock()
+}
+
+// InjectWatcherError 向所有监听器的错误通道投递 err
+func (c *ChaosFS) InjectWatcherError(err error) {
+	for _, w := range c.activeWatchers() {
+		w.sendError(err)
+	}
+}
+
+// BreakWatchers 关闭所有监听器的通道，模拟监听器意外失效（用于验证自动重建）
+func (c *ChaosFS) BreakWatchers() {
+	for _, w := range c.activeWatchers() {
+		w.Close()
+	}
+}
+
+// activeWatchers 返回尚未关闭的监听器，并清理已关闭的
+func (c *ChaosFS) activeWatchers() []*chaosWatcher {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	active := c.watchers[:0]
+	for _, w := range c.watchers {
+		select {
+		case <-w.done:
+		default:
+			active = append(active, w)
+		}
+	}
+	c.watchers = active
+	return append([]*chaosWatcher(nil), active...)
+}
+
+// Open 打开文件，应用待注入的读取故障
+func (c *ChaosFS) Open(name string) (fs.File, error) {
+	c.mu.Lock()
+	readErr, corrupt, truncate := c.readErr, c.corrupt, c.truncate
+	c.readErr, c.corrupt, c.truncate = nil, false, -1
+	c.mu.Unlock()
+
+	if readErr != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: readErr}
+	}
+	f, err := c.base.Open(name)
+	if err != nil || (!corrupt && truncate < 0) {
+		return f, err
+	}
+	defer f.Close()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return nil, err
+	}
+	if corrupt {
+		data = []byte("\x00this line is not KEY=VALUE\n")
+	} else if truncate < len(data) {
+		data = data[:truncate]
+	}
+	return &memFile{Reader: bytes.NewReader(data), info: fi, size: int64(len(data))}, nil
+}
+
+// Stat 返回底层文件系统的文件信息
+func (c *ChaosFS) Stat(name string) (fs.FileInfo, error) {
+	return c.base.Stat(name)
+}
+
+// Watch 创建一个可以注入故障的监听器
+func (c *ChaosFS) Watch() (loadenv.Watcher, error) {
+	base, err := c.base.Watch()
+	if err != nil {
+		return nil, err
+	}
+	w := &chaosWatcher{
+		Watcher: base,
+		events:  make(chan fsnotify.Event),
+		errors:  make(chan error),
+		done:    make(chan struct{}),
+	}
+	go w.forward()
+
+	c.mu.Lock()
+	c.watchers = append(c.watchers, w)
+	c.mu.Unlock()
+	return w, nil
+}
+
+// chaosWatcher 转发底层监听器的事件，并允许注入错误或提前关闭
+type chaosWatcher struct {
+	loadenv.Watcher
+	events chan fsnotify.Event
+	errors chan error
+	done   chan struct{}
+	once   sync.Once
+}
+
+func (w *chaosWatcher) Events() <-chan fsnotify.Event { return w.events }
+
+func (w *chaosWatcher) Errors() <-chan error { return w.errors }
+
+func (w *chaosWatcher) Close() error {
+	var err error
+	w.once.Do(func() {
+		close(w.done)
+		err = w.Watcher.Close()
+	})
+	return err
+}
+
+func (w *chaosWatcher) sendError(err error) {
+	select {
+	case w.errors <- err:
+	case <-w.done:
+	}
+}
+
+// forward 将底层事件转发到自己的通道，关闭后关闭通道
+func (w *chaosWatcher) forward() {
+	defer close(w.events)
+	defer close(w.errors)
+	for {
+		select {
+		case ev, ok := <-w.Watcher.Events():
+			if !ok {
+				return
+			}
+			select {
+			case w.events <- ev:
+			case <-w.done:
+				return
+			}
+		case err, ok := <-w.Watcher.Errors():
+			if !ok {
+				return
+			}
+			w.sendError(err)
+		case <-w.done:
+			return
+		}
+	}
+}
+
+// memFile 内存中的文件内容，保留原文件的元信息（大小除外）
+type memFile struct {
+	*bytes.Reader
+	info fs.FileInfo
+	size int64
+}
+
+func (f *memFile) Stat() (fs.FileInfo, error) { return sizedInfo{f.info, f.size}, nil }
+
+func (f *memFile) Close() error { return nil }
+
+type sizedInfo struct {
+	fs.FileInfo
+	size int64
+}
+
+func (i sizedInfo) Size() int64 { return i.size }
@@ -0,0 +1,96 @@
+// Package loadenvtest 提供在单元测试中使用 loadenv 的辅助工具
+package loadenvtest
+
+import (
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/solorez/loadenv"
+)
+
+// Env 测试用环境：一个写在临时目录中的环境文件及其独立的加载器
+type Env struct {
+	*loadenv.Loader
+	Path  string     // 临时环境文件路径
+	Clock *FakeClock // 加载器使用的模拟时间源
+	Chaos *ChaosFS   // 加载器使用的文件系统，可注入读取故障
+
+	t testing.TB
+}
+
+// WithEnv 将 env 写入临时 .env 文件，创建独立的加载器并执行 fn
+//
+// 加载器开启热重载但不监听真实文件，文件变更通过 Touch 投递、防抖窗口通过 Clock.Advance 推进；
+// fn 返回后关闭加载器，并将进程环境变量恢复为调用前的状态
+func WithEnv(t testing.TB, env map[string]string, fn func(e *Env)) {
+	t.Helper()
+	withEnv(t, env, true, fn)
+}
+
+// WithWatchedEnv 与 WithEnv 相同，但加载器通过 Chaos 创建文件监听器，
+// 可以用 Chaos.InjectWatcherError 和 Chaos.BreakWatchers 注入监听故障，结果反映在 Stats 中；
+// 真实的文件事件同样触发重载，防抖窗口仍通过 Clock.Advance 推进
+func WithWatchedEnv(t testing.TB, env map[string]string, fn func(e *Env)) {
+	t.Helper()
+	withEnv(t, env, false, fn)
+}
+
+// withEnv 实现 WithEnv 和 WithWatchedEnv，manual 为 true 时不创建文件监听器
+func withEnv(t testing.TB, env map[string]string, manual bool, fn func(e *Env)) {
+	t.Helper()
+
+	saved := loadenv.CaptureOSEnv()
+	defer loadenv.RestoreOSEnv(saved)
+
+	e := &Env{
+		Path:  filepath.Join(t.TempDir(), ".env"),
+		Clock: NewFakeClock(time.Unix(0, 0)),
+		Chaos: NewChaosFS(loadenv.OSFS()),
+		t:     t,
+	}
+	e.Write(env)
+
+	l, err := loadenv.New(loadenv.Config{
+		FilePath:     e.Path,
+		Logger:       log.New(io.Discard, "", 0),
+		HotReload:    true,
+		ManualEvents: manual,
+		Clock:        e.Clock,
+		FS:           e.Chaos,
+	})
+	if err != nil {
+		t.Fatalf("loadenvtest: init loader: %v", err)
+	}
+	defer l.Close()
+	e.Loader = l
+
+	fn(e)
+}
+
+// Write 用 env 覆盖环境文件内容，不会自动触发重载
+func (e *Env) Write(env map[string]string) {
+	e.t.Helper()
+
+	if err := os.WriteFile(e.Path, loadenv.Marshal(env), 0o600); err != nil {
+		e.t.Fatalf("loadenvtest: write %s: %v", e.Path, err)
+	}
+}
+
+// TriggerReload 同步执行一次重载，不依赖文件监听和防抖延迟
+func (e *Env) TriggerReload() loadenv.ReloadResult {
+	e.t.Helper()
+	result, err := e.Reload()
+	if err != nil {
+		e.t.Fatalf("loadenvtest: reload: %v", err)
+	}
+	return result
+}
+
+// Touch 投递一次文件变更事件，重载在 Clock 推进超过防抖延迟后发生
+func (e *Env) Touch() {
+	e.Notify()
+}
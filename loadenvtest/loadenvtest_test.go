@@ -0,0 +1,42 @@
+package loadenvtest
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/solorez/loadenv"
+)
+
+// waitStats 等待 Stats 满足 cond，监听器错误由加载器的监听协程异步记录
+func waitStats(t *testing.T, e *Env, cond func(loadenv.Stats) bool) loadenv.Stats {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		s := e.Stats()
+		if cond(s) {
+			return s
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for stats, last: %+v", s)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestWithWatchedEnvWatcherFaults(t *testing.T) {
+	WithWatchedEnv(t, map[string]string{"CHAOS_A": "1"}, func(e *Env) {
+		boom := errors.New("inotify queue overflow")
+		e.Chaos.InjectWatcherError(boom)
+		s := waitStats(t, e, func(s loadenv.Stats) bool { return s.WatcherErrors == 1 })
+		if !errors.Is(s.LastWatcherErr, boom) {
+			t.Fatalf("LastWatcherErr = %v, want %v", s.LastWatcherErr, boom)
+		}
+
+		e.Chaos.BreakWatchers()
+		waitStats(t, e, func(s loadenv.Stats) bool { return s.WatcherRestarts == 1 })
+		// 重建后的监听器同样可以注入故障
+		e.Chaos.InjectWatcherError(boom)
+		waitStats(t, e, func(s loadenv.Stats) bool { return s.WatcherErrors == 2 })
+	})
+}
@@ -0,0 +1,41 @@
+package loadenv
+
+import "log/slog"
+
+// BindLevel 将键（如 LOG_LEVEL）绑定到日志级别，每次重载后以新值调用 set
+//
+// 适用于任意日志库，例如：
+//
+//	zap:     l.BindLevel("LOG_LEVEL", func(v string) error { return atom.UnmarshalText([]byte(v)) })
+//	zerolog: l.BindLevel("LOG_LEVEL", func(v string) error {
+//		lvl, err := zerolog.ParseLevel(v)
+//		if err == nil {
+//			zerolog.SetGlobalLevel(lvl)
+//		}
+//		return err
+//	})
+//
+// 键未设置时不调用 set；set 返回错误时保留原级别并记录日志。
+// 返回首次绑定时 set 的错误（包装为 ValueError）
+func (l *Loader) BindLevel(key string, set func(level string) error) error {
+	return l.bindKey(key, "log level", set)
+}
+
+// BindSlogLevel 将键绑定到 slog.LevelVar，接受 debug、info、warn、error 及 "warn+2" 这样的偏移写法（不区分大小写）
+func (l *Loader) BindSlogLevel(key string, lv *slog.LevelVar) error {
+	return l.BindLevel(key, slogSetter(lv))
+}
+
+// BindLevel 在默认加载器上绑定日志级别，参见 Loader.BindLevel；未初始化时只从进程环境变量设置一次
+func BindLevel(key string, set func(level string) error) error {
+	return bindKeyDefault(key, "log level", set)
+}
+
+// BindSlogLevel 在默认加载器上绑定 slog.LevelVar，参见 Loader.BindSlogLevel
+func BindSlogLevel(key string, lv *slog.LevelVar) error {
+	return BindLevel(key, slogSetter(lv))
+}
+
+func slogSetter(lv *slog.LevelVar) func(string) error {
+	return func(v string) error { return lv.UnmarshalText([]byte(v)) }
+}
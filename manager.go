@@ -0,0 +1,218 @@
+package loadenv
+
+import (
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// Manager 多租户加载器管理器
+//
+// 每个租户对应 root 下的一个子目录（例如 tenants/<id>/.env），所有租户共用一个 fsnotify 监听器：
+// 监听的是租户目录而不是文件，数量与租户数相同，并能感知新增和删除的租户。
+// 租户加载器运行在 Isolated 模式下，配置互不影响，也不会写入进程环境变量
+type Manager struct {
+	root string
+	cfg  Config
+
+	watcher Watcher
+	closeCh chan struct{}
+	closed  sync.Once
+
+	mu      sync.RWMutex
+	tenants map[string]*Loader
+}
+
+// NewManager 扫描 root 下的租户目录并为每个租户创建加载器
+//
+// cfg 是所有租户共用的配置模板，其中 FilePath 为租户目录内的文件名（默认 .env）；
+// HotReload 为 true 时监听文件变化
+func NewManager(root string, cfg Config) (*Manager, error) {
+	if cfg.FilePath == "" {
+		cfg.FilePath = ".env"
+	}
+	if cfg.Logger == nil {
+		cfg.Logger = defaultLogger()
+	}
+	if cfg.FS == nil {
+		cfg.FS = osFS{}
+	}
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &Manager{
+		root:    absRoot,
+		cfg:     cfg,
+		closeCh: make(chan struct{}),
+		tenants: make(map[string]*Loader),
+	}
+
+	if cfg.HotReload {
+		if m.watcher, err = cfg.FS.Watch(); err != nil {
+			return nil, err
+		}
+		if err := m.watcher.Add(absRoot); err != nil {
+			m.watcher.Close()
+			return nil, err
+		}
+	}
+
+	entries, err := fs.ReadDir(cfg.FS, absRoot)
+	if err != nil {
+		m.Close()
+		return nil, err
+	}
+	for _, e := range entries {
+		if e.IsDir() {
+			if err := m.addTenant(e.Name()); err != nil {
+				m.Close()
+				return nil, err
+			}
+		}
+	}
+
+	if m.watcher != nil {
+		go m.watchEvents()
+	}
+	return m, nil
+}
+
+// addTenant 开始监听租户目录并为其创建加载器，目录中暂时没有环境文件时只监听、等待文件创建；
+// 租户已存在时（例如重复的创建事件）保留现有的加载器
+func (m *Manager) addTenant(id string) error {
+	dir := filepath.Join(m.root, id)
+	path := filepath.Join(dir, m.cfg.FilePath)
+	if m.watcher != nil {
+		if err := m.watcher.Add(dir); err != nil {
+			return err
+		}
+	}
+	if _, err := m.cfg.FS.Stat(path); err != nil || m.Tenant(id) != nil {
+		return nil
+	}
+
+	cfg := m.cfg
+	cfg.FilePath = path
+	cfg.Isolated = true
+	cfg.ManualEvents = true
+	l, err := New(cfg)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	if _, exists := m.tenants[id]; exists {
+		m.mu.Unlock()
+		l.Close()
+		return nil
+	}
+	m.tenants[id] = l
+	m.mu.Unlock()
+	m.cfg.Logger.Printf("Tenant %s loaded from %s", id, path)
+	return nil
+}
+
+// removeTenant 关闭并移除租户
+func (m *Manager) removeTenant(id string) {
+	m.mu.Lock()
+	l, ok := m.tenants[id]
+	delete(m.tenants, id)
+	m.mu.Unlock()
+	if ok {
+		l.Close()
+		m.cfg.Logger.Printf("Tenant %s removed", id)
+	}
+}
+
+// watchEvents 将文件事件路由到对应租户
+func (m *Manager) watchEvents() {
+	defer m.watcher.Close()
+	for {
+		select {
+		case event, ok := <-m.watcher.Events():
+			if !ok {
+				return
+			}
+			m.route(event)
+		case err, ok := <-m.watcher.Errors():
+			if !ok {
+				return
+			}
+			m.cfg.Logger.Printf("Watcher error: %v", err)
+		case <-m.closeCh:
+			return
+		}
+	}
+}
+
+func (m *Manager) route(event fsnotify.Event) {
+	dir, name := filepath.Split(event.Name)
+	dir = filepath.Clean(dir)
+
+	// root 下的目录增删对应租户的增删
+	if dir == m.root {
+		switch {
+		case event.Has(fsnotify.Create):
+			if fi, err := m.cfg.FS.Stat(event.Name); err == nil && fi.IsDir() {
+				if err := m.addTenant(name); err != nil {
+					m.cfg.Logger.Printf("Tenant %s failed to load: %v", name, err)
+				}
+			}
+		case event.Has(fsnotify.Remove), event.Has(fsnotify.Rename):
+			m.removeTenant(name)
+		}
+		return
+	}
+
+	if filepath.Dir(dir) != m.root || name != filepath.Base(m.cfg.FilePath) {
+		return
+	}
+	id := filepath.Base(dir)
+	if l := m.Tenant(id); l != nil {
+		l.Notify()
+	} else if event.Has(fsnotify.Create) {
+		// 租户目录先于环境文件创建
+		if err := m.addTenant(id); err != nil {
+			m.cfg.Logger.Printf("Tenant %s failed to load: %v", id, err)
+		}
+	}
+}
+
+// Tenant 返回租户的加载器，租户不存在时返回 nil
+func (m *Manager) Tenant(id string) *Loader {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.tenants[id]
+}
+
+// Tenants 返回所有租户 ID（已排序）
+func (m *Manager) Tenants() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	ids := make([]string, 0, len(m.tenants))
+	for id := range m.tenants {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// Close 停止监听并关闭所有租户加载器
+func (m *Manager) Close() {
+	m.closed.Do(func() {
+		close(m.closeCh)
+		if m.watcher != nil {
+			m.watcher.Close()
+		}
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		for _, l := range m.tenants {
+			l.Close()
+		}
+	})
+}
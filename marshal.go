@@ -0,0 +1,27 @@
+package loadenv
+
+import (
+	"strings"
+)
+
+// Marshal 将键值对序列化为 .env 格式，键按字母排序，结果可被 ParseBytes 原样解析回来
+func Marshal(env map[string]string) []byte {
+	var b strings.Builder
+	for _, k := range sortedKeys(env) {
+		b.WriteString(k)
+		b.WriteByte('=')
+		b.WriteString(quoteValue(env[k]))
+		b.WriteByte('\n')
+	}
+	return []byte(b.String())
+}
+
+var valueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`, "$", `\$`)
+
+// quoteValue 在需要时为值加上双引号
+func quoteValue(v string) string {
+	if v != "" && !strings.ContainsAny(v, " \t\r\n\"'`\\$#=") {
+		return v
+	}
+	return `"` + valueEscaper.Replace(v) + `"`
+}
@@ -0,0 +1,16 @@
+package loadenv
+
+import "maps"
+
+// Merge 按顺序合并多份键值对，与加载器合并来源的规则一致：后面的值覆盖前面的值。
+// 返回新的 map，不修改参数
+func Merge(base map[string]string, overlays ...map[string]string) map[string]string {
+	merged := maps.Clone(base)
+	if merged == nil {
+		merged = make(map[string]string)
+	}
+	for _, o := range overlays {
+		maps.Copy(merged, o)
+	}
+	return merged
+}
@@ -0,0 +1,306 @@
+package loadenv
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// KeyMeta 通过注释注解声明的键元数据，写在键上方紧挨着的注释中，例如
+//
+//	# desc: worker pool size
+//	# type: int  # required
+//	WORKERS=8
+//
+// 一行注释以 type、required、optional、desc 或 description 开头时才视为注解，同一行中的多项以空白后的 # 分隔；
+// 其余形如 name: value 的项保存在 Attrs 中，普通注释被忽略。
+// 开启 Config.Annotations 后，环境文件和 SchemaFile 中的注解在加载时生效（环境文件中同一个键的注解整体取代 SchemaFile 中的），
+// 值不符合 Type 或 Required 的键没有非空值时加载失败
+type KeyMeta struct {
+	Key         string            `json:"key"`
+	Type        string            `json:"type,omitempty"`        // 值的类型，参见 MetaTypes
+	Required    bool              `json:"required,omitempty"`    // 必须有非空值
+	Description string            `json:"description,omitempty"` // 键的说明
+	Attrs       map[string]string `json:"attrs,omitempty"`       // 其他注解
+	File        string            `json:"file,omitempty"`        // 声明注解的文件，由 ParseMetadata 得到时为空
+}
+
+// MetaTypes 注解中 type 可以使用的类型及其格式，可以在 New 之前添加自定义类型
+var MetaTypes = map[string]Format{
+	"string":   {Name: "string", Check: func(string) bool { return true }},
+	"int":      {Name: "integer", Check: func(s string) bool { _, err := strconv.ParseInt(s, 10, 64); return err == nil }},
+	"uint":     {Name: "unsigned integer", Check: func(s string) bool { _, err := strconv.ParseUint(s, 10, 64); return err == nil }},
+	"float":    {Name: "number", Check: func(s string) bool { _, err := strconv.ParseFloat(s, 64); return err == nil }},
+	"bool":     {Name: "boolean", Check: func(s string) bool { _, err := strconv.ParseBool(s); return err == nil }},
+	"duration": {Name: "duration", Check: func(s string) bool { _, err := time.ParseDuration(s); return err == nil }},
+	"url":      {Name: "URL", Check: isURL},
+	"email":    FormatEmail,
+	"uuid":     FormatUUID,
+	"hostname": FormatHostname,
+	"port":     FormatPort,
+}
+
+func isURL(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && u.Scheme != "" && u.Host != ""
+}
+
+// ParseMetadata 解析 .env 格式内容中键上方的注释注解，返回有注解的键的元数据
+//
+// 与 ParseBytes 一样是纯函数；注解中的未知类型返回错误
+func ParseMetadata(src []byte) (map[string]KeyMeta, error) {
+	_, meta, err := parseMeta(src, nil, ParseOptions{})
+	return meta, err
+}
+
+// parseMeta 解析内容及其中的注解，lookup 和 opts 参见 parse
+func parseMeta(src []byte, lookup func(string) (string, bool), opts ParseOptions) (map[string]string, map[string]KeyMeta, error) {
+	p := newParser(src, lookup)
+	p.opts = opts
+	p.notes = make(map[string][]string)
+	if err := p.run(); err != nil {
+		return nil, nil, err
+	}
+	meta := make(map[string]KeyMeta)
+	for key, lines := range p.notes {
+		m, ok, err := annotations(key, lines)
+		if err != nil {
+			return nil, nil, err
+		}
+		if ok {
+			meta[key] = m
+		}
+	}
+	return p.env, meta, nil
+}
+
+// annotations 从注释行中解析键的注解，没有注解时 ok 为 false
+func annotations(key string, lines []string) (m KeyMeta, ok bool, err error) {
+	m.Key = key
+	for _, line := range lines {
+		items := splitAnnotations(line)
+		if len(items) == 0 || !isAnnotation(items[0]) {
+			continue
+		}
+		ok = true
+		for _, item := range items {
+			name, value, _ := strings.Cut(item, ":")
+			name, value = strings.ToLower(strings.TrimSpace(name)), strings.TrimSpace(value)
+			switch name {
+			case "type":
+				if _, known := MetaTypes[value]; !known {
+					return m, false, fmt.Errorf("loadenv: %s: unknown type %q in annotation", key, value)
+				}
+				m.Type = value
+			case "required":
+				m.Required = true
+			case "optional":
+				m.Required = false
+			case "desc", "description":
+				m.Description = value
+			default:
+				if value != "" && validKey(name) {
+					if m.Attrs == nil {
+						m.Attrs = make(map[string]string)
+					}
+					m.Attrs[name] = value
+				}
+			}
+		}
+	}
+	return m, ok, nil
+}
+
+// splitAnnotations 以空白后的 # 切分一行注释
+func splitAnnotations(line string) []string {
+	var items []string
+	for {
+		i := inlineComment(line)
+		if i < 0 {
+			break
+		}
+		items = append(items, strings.TrimSpace(line[:i]))
+		line = line[i+1:]
+	}
+	if line = strings.TrimSpace(line); line != "" {
+		items = append(items, line)
+	}
+	return items
+}
+
+func isAnnotation(item string) bool {
+	name, _, found := strings.Cut(item, ":")
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "type", "desc", "description":
+		return found
+	case "required", "optional":
+		return true
+	}
+	return false
+}
+
+// readMeta 合并 SchemaFile 与环境文件（fileMeta）中的注解，结果保存在 l.meta 中，调用方需持有 mu
+func (l *Loader) readMeta(fileMeta map[string]KeyMeta, absPath string) error {
+	meta, err := l.readSchemaMeta()
+	if err != nil {
+		return err
+	}
+	if meta == nil {
+		meta = make(map[string]KeyMeta, len(fileMeta))
+	}
+	for key, m := range fileMeta {
+		m.File = absPath
+		meta[key] = m
+	}
+	l.meta = meta
+	return nil
+}
+
+// readSchemaMeta 读取 SchemaFile 中的注解，未配置时返回 nil
+func (l *Loader) readSchemaMeta() (map[string]KeyMeta, error) {
+	if l.cfg.SchemaFile == "" {
+		return nil, nil
+	}
+	path := l.sidecar(l.cfg.SchemaFile)
+	data, err := fs.ReadFile(l.cfg.FS, path)
+	if err != nil {
+		return nil, err
+	}
+	if data, err = decode(data, l.cfg.Encoding); err != nil {
+		return nil, err
+	}
+	meta, err := ParseMetadata(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	for key, m := range meta {
+		m.File = path
+		meta[key] = m
+	}
+	return meta, nil
+}
+
+// checkMeta 按注解校验即将加载的变量，Required 的键也可以由非 Isolated 时的进程环境变量提供；返回发现的所有问题
+func (l *Loader) checkMeta(meta map[string]KeyMeta, env map[string]string) error {
+	var (
+		missing []string
+		errs    []error
+	)
+	files := map[string]bool{}
+	keys := make([]string, 0, len(meta))
+	for key := range meta {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		m := meta[key]
+		v, ok := env[key]
+		if !ok && !l.cfg.Isolated {
+			v, ok = os.LookupEnv(key)
+		}
+		if m.Required && (!ok || v == "") {
+			missing = append(missing, key)
+			files[m.File] = true
+			continue
+		}
+		if ok && m.Type != "" {
+			f, ok := l.values.format(m.Type)
+			if !ok {
+				f = MetaTypes[m.Type]
+			}
+			errs = append(errs, f.validate(key, v))
+		}
+	}
+	if len(missing) > 0 {
+		errs = append([]error{&MissingKeysError{Schema: strings.Join(sortedSet(files), ", "), Keys: missing}}, errs...)
+	}
+	return joinErrors(errs...)
+}
+
+// describe 为错误补充注解中的键说明：ValueError 补充 Description，MissingKeysError 补充 Descriptions
+func describe(meta map[string]KeyMeta, err error) error {
+	if len(meta) == 0 {
+		return err
+	}
+	if me, ok := err.(*MultiError); ok {
+		for _, err := range me.Errors {
+			describe(meta, err)
+		}
+		return me
+	}
+	var ve *ValueError
+	if errors.As(err, &ve) && ve.Description == "" {
+		ve.Description = meta[ve.Key].Description
+	}
+	var me *MissingKeysError
+	if errors.As(err, &me) && me.Descriptions == nil {
+		for _, key := range me.Keys {
+			if desc := meta[key].Description; desc != "" {
+				if me.Descriptions == nil {
+					me.Descriptions = make(map[string]string)
+				}
+				me.Descriptions[key] = desc
+			}
+		}
+	}
+	return err
+}
+
+// describe 按快照中的注解为错误补充键说明
+func (s *Snapshot) describe(err error) error {
+	if s == nil {
+		return err
+	}
+	return describe(s.meta, err)
+}
+
+// Describe 返回键的注解，没有注解时 ok 为 false
+func (s *Snapshot) Describe(key string) (KeyMeta, bool) {
+	if s == nil {
+		return KeyMeta{}, false
+	}
+	m, ok := s.meta[key]
+	m.Attrs = maps.Clone(m.Attrs)
+	return m, ok
+}
+
+// Metadata 返回所有有注解的键的元数据（按键排序），可用于生成配置文档
+func (s *Snapshot) Metadata() []KeyMeta {
+	if s == nil {
+		return nil
+	}
+	out := make([]KeyMeta, 0, len(s.meta))
+	for _, m := range s.meta {
+		m.Attrs = maps.Clone(m.Attrs)
+		out = append(out, m)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Key < out[j].Key })
+	return out
+}
+
+// Describe 参见 Snapshot.Describe
+func (l *Loader) Describe(key string) (KeyMeta, bool) {
+	return l.Snapshot().Describe(key)
+}
+
+// Metadata 参见 Snapshot.Metadata
+func (l *Loader) Metadata() []KeyMeta {
+	return l.Snapshot().Metadata()
+}
+
+// Describe 返回默认加载器中键的注解，参见 Snapshot.Describe
+func Describe(key string) (KeyMeta, bool) {
+	return current().Describe(key)
+}
+
+// Metadata 返回默认加载器中所有键的注解，参见 Snapshot.Metadata
+func Metadata() []KeyMeta {
+	return current().Metadata()
+}
@@ -0,0 +1,64 @@
+package loadenv
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// VersionHeader 携带配置版本（快照校验和的前 12 位）的 HTTP 响应头
+const VersionHeader = "X-Config-Version"
+
+// MiddlewareOptions HTTP 中间件的行为设置
+type MiddlewareOptions struct {
+	// RejectDuringReload 重载进行中时以 503 拒绝请求，避免请求读到半途切换的配置
+	RejectDuringReload bool
+	// RetryAfter 拒绝时通过 Retry-After 告知客户端的重试间隔，默认 1 秒
+	RetryAfter time.Duration
+}
+
+// Middleware 在每个响应中写入 X-Config-Version，便于在边缘观察配置的发布进度；
+// 请求的 context 携带处理开始时的快照，处理函数通过 FromContext 读取
+func (l *Loader) Middleware(next http.Handler) http.Handler {
+	return l.MiddlewareWith(MiddlewareOptions{})(next)
+}
+
+// MiddlewareWith 返回按 opts 配置的中间件，参见 Loader.Middleware
+func (l *Loader) MiddlewareWith(opts MiddlewareOptions) func(http.Handler) http.Handler {
+	return middleware(func() *Loader { return l }, opts)
+}
+
+// Middleware 使用默认加载器的中间件，参见 Loader.Middleware；未初始化时写入空配置的版本
+func Middleware(next http.Handler) http.Handler {
+	return MiddlewareWith(MiddlewareOptions{})(next)
+}
+
+// MiddlewareWith 使用默认加载器、按 opts 配置的中间件，参见 Loader.MiddlewareWith
+func MiddlewareWith(opts MiddlewareOptions) func(http.Handler) http.Handler {
+	return middleware(std.Load, opts)
+}
+
+// middleware 在请求时获取加载器，使包级中间件可以在 InitEnv 之前创建
+func middleware(loader func() *Loader, opts MiddlewareOptions) func(http.Handler) http.Handler {
+	if opts.RetryAfter <= 0 {
+		opts.RetryAfter = time.Second
+	}
+	retryAfter := strconv.Itoa(int((opts.RetryAfter + time.Second - 1) / time.Second))
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			l := loader()
+			if opts.RejectDuringReload && l != nil && l.reloading.Load() {
+				w.Header().Set("Retry-After", retryAfter)
+				http.Error(w, "configuration reload in progress", http.StatusServiceUnavailable)
+				return
+			}
+			var snap *Snapshot
+			if l != nil {
+				snap = l.Snapshot()
+			}
+			w.Header().Set(VersionHeader, snap.ShortChecksum())
+			next.ServeHTTP(w, r.WithContext(NewContext(r.Context(), snap)))
+		})
+	}
+}
@@ -0,0 +1,154 @@
+package loadenv
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// DefaultVersionKey 默认保存环境文件模式版本的键
+const DefaultVersionKey = "CONFIG_VERSION"
+
+// MigrationFunc 原地修改环境文件中的键值对，把它从上一个版本升级到新版本
+type MigrationFunc func(env map[string]string) error
+
+// Migration 一次模式版本升级
+//
+// 加载时读取环境文件中 VersionKey 的值（缺省为版本 0），
+// 依次执行所有 Version 大于它的迁移，并把 VersionKey 设为最新版本。
+// 迁移只作用于环境文件本身，默认值文件和其他来源应当已经使用最新的键
+type Migration struct {
+	Version     int             // 迁移后的版本，必须大于 0 且严格递增
+	Description string          // 迁移说明，用于日志，如 "split DATABASE_ADDR"
+	Steps       []MigrationFunc // 按顺序执行的步骤，参见 RenameKey、SplitValue、ConvertValue、RemoveKey
+}
+
+// MigrationError 迁移失败或环境文件的版本无法识别
+type MigrationError struct {
+	From, To int // 迁移前的版本与目标版本
+	Err      error
+}
+
+func (e *MigrationError) Error() string {
+	return fmt.Sprintf("loadenv: migrate config from version %d to %d: %v", e.From, e.To, e.Err)
+}
+
+func (e *MigrationError) Unwrap() error { return e.Err }
+
+// RenameKey 把键 from 改名为 to；to 已存在时保留 to 的值并删除 from
+func RenameKey(from, to string) MigrationFunc {
+	return func(env map[string]string) error {
+		v, ok := env[from]
+		if !ok {
+			return nil
+		}
+		if _, exists := env[to]; !exists {
+			env[to] = v
+		}
+		delete(env, from)
+		return nil
+	}
+}
+
+// SplitValue 把键 key 的值按 sep 拆分到 keys 中并删除 key，拆分后的段数必须与 keys 一致，
+// 例如 SplitValue("DATABASE_ADDR", ":", "DB_HOST", "DB_PORT")
+func SplitValue(key, sep string, keys ...string) MigrationFunc {
+	return func(env map[string]string) error {
+		v, ok := env[key]
+		if !ok {
+			return nil
+		}
+		parts := strings.SplitN(v, sep, len(keys))
+		if len(parts) != len(keys) {
+			return fmt.Errorf("split %s: want %d parts separated by %q, got %d", key, len(keys), sep, len(parts))
+		}
+		for i, k := range keys {
+			env[k] = parts[i]
+		}
+		delete(env, key)
+		return nil
+	}
+}
+
+// ConvertValue 用 fn 改写键 key 的值，用于格式变化，例如秒数改为 Go 时长
+func ConvertValue(key string, fn func(value string) (string, error)) MigrationFunc {
+	return func(env map[string]string) error {
+		v, ok := env[key]
+		if !ok {
+			return nil
+		}
+		nv, err := fn(v)
+		if err != nil {
+			return fmt.Errorf("convert %s: %w", key, err)
+		}
+		env[key] = nv
+		return nil
+	}
+}
+
+// RemoveKey 删除不再使用的键
+func RemoveKey(keys ...string) MigrationFunc {
+	return func(env map[string]string) error {
+		for _, k := range keys {
+			delete(env, k)
+		}
+		return nil
+	}
+}
+
+// checkMigrations 校验迁移的版本号严格递增
+func checkMigrations(ms []Migration) error {
+	prev := 0
+	for _, m := range ms {
+		if m.Version <= prev {
+			return fmt.Errorf("loadenv: migration versions must be positive and strictly increasing, got %d after %d", m.Version, prev)
+		}
+		prev = m.Version
+	}
+	return nil
+}
+
+// versionKey 返回保存模式版本的键
+func (l *Loader) versionKey() string {
+	if l.cfg.VersionKey != "" {
+		return l.cfg.VersionKey
+	}
+	return DefaultVersionKey
+}
+
+// migrate 把环境文件中的键值对原地升级到最新的模式版本，调用方需持有 mu
+func (l *Loader) migrate(env map[string]string) error {
+	if len(l.cfg.Migrations) == 0 {
+		return nil
+	}
+	key := l.versionKey()
+	latest := l.cfg.Migrations[len(l.cfg.Migrations)-1].Version
+
+	from := 0
+	if v, ok := env[key]; ok {
+		n, err := strconv.Atoi(strings.TrimSpace(v))
+		if err != nil || n < 0 {
+			return &MigrationError{From: n, To: latest, Err: fmt.Errorf("invalid %s %q", key, v)}
+		}
+		from = n
+	}
+	if from > latest {
+		return &MigrationError{From: from, To: latest, Err: fmt.Errorf("file is newer than the latest known version %d", latest)}
+	}
+
+	version := from
+	for _, m := range l.cfg.Migrations {
+		if m.Version <= from {
+			continue
+		}
+		for _, step := range m.Steps {
+			if err := step(env); err != nil {
+				return &MigrationError{From: version, To: m.Version, Err: err}
+			}
+		}
+		l.logger.Printf("Migrated config from version %d to %d: %s", version, m.Version, m.Description)
+		version = m.Version
+	}
+	env[key] = strconv.Itoa(latest)
+	return nil
+}
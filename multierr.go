@@ -0,0 +1,48 @@
+package loadenv
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MultiError 一次校验中发现的多个问题（缺失的键、类型错误、策略不通过等），按发现的顺序排列
+//
+// 加载时会完成所有校验再报告，运维人员可以一次修正全部问题。
+// errors.Is 和 errors.As 可以匹配其中任意一个错误，例如
+//
+//	var missing *loadenv.MissingKeysError
+//	if errors.As(err, &missing) { ... }
+type MultiError struct {
+	Errors []error
+}
+
+func (e *MultiError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "loadenv: %d problems in config:", len(e.Errors))
+	for _, err := range e.Errors {
+		b.WriteString("\n  - ")
+		b.WriteString(strings.TrimPrefix(err.Error(), "loadenv: "))
+	}
+	return b.String()
+}
+
+func (e *MultiError) Unwrap() []error { return e.Errors }
+
+// joinErrors 合并 errs 中的非 nil 错误：没有时返回 nil，只有一个时原样返回，否则返回 MultiError（嵌套的 MultiError 被展开）
+func joinErrors(errs ...error) error {
+	var out []error
+	for _, err := range errs {
+		if me, ok := err.(*MultiError); ok {
+			out = append(out, me.Errors...)
+		} else if err != nil {
+			out = append(out, err)
+		}
+	}
+	switch len(out) {
+	case 0:
+		return nil
+	case 1:
+		return out[0]
+	}
+	return &MultiError{Errors: out}
+}
@@ -0,0 +1,43 @@
+package loadenv
+
+import "strings"
+
+// TrimMode 值首尾空白的处理方式
+type TrimMode int
+
+const (
+	TrimUnquoted TrimMode = iota // 去掉未加引号值首尾的空白，引号内原样保留（默认，与 godotenv 相同）
+	TrimAll                      // 引号内的值也去掉首尾空白
+	TrimNone                     // 未加引号的值也保留首尾空白；行内注释之前的空白仍属于注释
+)
+
+// ParseOptions 解析和规范化值的方式，零值与 ParseBytes 相同
+//
+// 通过 Config.Parse 配置后，加载器读取的环境文件、默认值文件和覆盖文件都按同样的规则解析，
+// 重载时的变更比较因此与加载一致；比较两个文件时应使用相同的选项解析，参见 ParseBytesWith
+type ParseOptions struct {
+	Trim                 TrimMode // 首尾空白的处理
+	KeepQuotes           bool     // 引号不是语法：值两端的引号原样保留，不处理转义、不跨行（变量引用仍然展开）
+	StripTrailingNewline bool     // 去掉值末尾的换行，例如以换行结尾的多行引号值
+
+	// DockerEnvFile 与 docker run --env-file 逐字节一致：每行一个 VAR=VAL，值原样保留，
+	// 没有引号、转义、变量引用、行内注释和续行；只有 VAR 的行取进程环境变量中的值（ParseBytesWith 中忽略）。
+	// 开启后其他选项不生效，同一个文件本地校验通过后交给 docker run 时得到相同的值
+	DockerEnvFile bool
+}
+
+// ParseBytesWith 按 opts 解析 .env 格式的内容，参见 ParseBytes
+func ParseBytesWith(src []byte, opts ParseOptions) (map[string]string, error) {
+	return parse(src, nil, opts)
+}
+
+// normalize 按选项规范化解析得到的值
+func (o ParseOptions) normalize(value string) string {
+	if o.Trim == TrimAll {
+		value = strings.TrimSpace(value)
+	}
+	if o.StripTrailingNewline {
+		value = strings.TrimRight(value, "\r\n")
+	}
+	return value
+}
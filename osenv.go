@@ -0,0 +1,33 @@
+package loadenv
+
+import "os"
+
+// OSEnv 进程环境变量的快照，由 CaptureOSEnv 创建
+type OSEnv map[string]string
+
+// CaptureOSEnv 保存当前进程环境变量
+func CaptureOSEnv() OSEnv {
+	return OSEnv(environMap())
+}
+
+// RestoreOSEnv 将进程环境变量恢复为 snapshot：删除之后新增的键，恢复被修改或删除的键，
+// 未变化的键不会被重新设置
+func RestoreOSEnv(snapshot OSEnv) error {
+	current := environMap()
+	for k := range current {
+		if _, ok := snapshot[k]; !ok {
+			if err := os.Unsetenv(k); err != nil {
+				return err
+			}
+		}
+	}
+	for k, v := range snapshot {
+		if cur, ok := current[k]; ok && cur == v {
+			continue
+		}
+		if err := os.Setenv(k, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
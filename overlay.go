@@ -0,0 +1,170 @@
+package loadenv
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Overlay 按时间表生效的覆盖文件，例如只在工作日 09:00–18:00 生效的 .env.peak
+//
+// 生效期间覆盖文件中的值覆盖环境文件中的同名键（Sources 仍然优先）；
+// 进入和离开生效时段时自动重载，变化会像普通重载一样触发钩子和订阅通知
+type Overlay struct {
+	File     string         // 覆盖文件路径，相对路径相对于环境文件所在目录
+	Schedule string         // 生效时段，格式为 "<星期> <HH:MM>-<HH:MM>"，如 "Mon-Fri 09:00-18:00"、"Sat,Sun 00:00-24:00"、"* 22:00-06:00"（跨午夜）
+	Location *time.Location // 解释时间表使用的时区，默认 time.Local
+}
+
+// schedule 解析后的生效时段
+type schedule struct {
+	days       [7]bool // 按 time.Weekday 索引
+	start, end int     // 一天中的分钟数，end 可以为 1440（24:00）；start > end 表示跨午夜
+	loc        *time.Location
+}
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// parseSchedule 解析 Overlay.Schedule
+func parseSchedule(spec string, loc *time.Location) (schedule, error) {
+	s := schedule{loc: loc}
+	if s.loc == nil {
+		s.loc = time.Local
+	}
+	days, hours, ok := strings.Cut(strings.TrimSpace(spec), " ")
+	if !ok {
+		return s, fmt.Errorf("loadenv: invalid schedule %q: want \"<days> <HH:MM>-<HH:MM>\"", spec)
+	}
+	for _, part := range strings.Split(days, ",") {
+		if part == "*" {
+			s.days = [7]bool{true, true, true, true, true, true, true}
+			continue
+		}
+		from, to, isRange := strings.Cut(strings.ToLower(part), "-")
+		first, ok1 := weekdays[from]
+		last, ok2 := weekdays[to]
+		if !isRange {
+			last, ok2 = first, ok1
+		}
+		if !ok1 || !ok2 {
+			return s, fmt.Errorf("loadenv: invalid schedule %q: unknown day %q", spec, part)
+		}
+		for d := first; ; d = (d + 1) % 7 {
+			s.days[d] = true
+			if d == last {
+				break
+			}
+		}
+	}
+	from, to, ok := strings.Cut(strings.TrimSpace(hours), "-")
+	var err1, err2 error
+	s.start, err1 = parseClock(from)
+	s.end, err2 = parseClock(to)
+	if !ok || err1 != nil || err2 != nil || s.start == s.end {
+		return s, fmt.Errorf("loadenv: invalid schedule %q: bad time range %q", spec, hours)
+	}
+	return s, nil
+}
+
+// parseClock 将 HH:MM 解析为一天中的分钟数，允许 24:00
+func parseClock(s string) (int, error) {
+	h, m, ok := strings.Cut(s, ":")
+	hour, err1 := strconv.Atoi(h)
+	minute, err2 := strconv.Atoi(m)
+	if !ok || err1 != nil || err2 != nil || hour < 0 || minute < 0 || minute > 59 || hour*60+minute > 24*60 {
+		return 0, fmt.Errorf("invalid time %q", s)
+	}
+	return hour*60 + minute, nil
+}
+
+// active 判断 t 是否在生效时段内
+func (s schedule) active(t time.Time) bool {
+	t = t.In(s.loc)
+	m := t.Hour()*60 + t.Minute()
+	if s.start < s.end {
+		return s.days[t.Weekday()] && m >= s.start && m < s.end
+	}
+	// 跨午夜：前半段属于当天，后半段属于前一天
+	return (s.days[t.Weekday()] && m >= s.start) || (s.days[(t.Weekday()+6)%7] && m < s.end)
+}
+
+// readOverlays 将当前生效的覆盖文件合并到 env 之上，并在 origins 中记录来源
+func (l *Loader) readOverlays(env, origins map[string]string) (map[string]string, error) {
+	now := l.cfg.Clock.Now()
+	for i, o := range l.cfg.Overlays {
+		if !l.schedules[i].active(now) {
+			continue
+		}
+		path := l.sidecar(o.File)
+		data, err := fs.ReadFile(l.cfg.FS, path)
+		if err != nil {
+			return nil, err
+		}
+		if data, err = decode(data, l.cfg.Encoding); err != nil {
+			return nil, err
+		}
+		overlay, err := parse(data, os.LookupEnv, l.cfg.Parse)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		if err := l.checkPlaintext(path, overlay); err != nil {
+			return nil, err
+		}
+		for key, value := range overlay {
+			origins[key] = "file:" + path
+			l.define(key, "file:"+path, value)
+		}
+		env = Merge(env, overlay)
+	}
+	return env, nil
+}
+
+// nextTransition 返回 now 之后第一个有覆盖文件进入或离开生效时段的时间（按分钟对齐），一周内没有时返回零值
+func (l *Loader) nextTransition(now time.Time) time.Time {
+	state := func(t time.Time) []bool {
+		s := make([]bool, len(l.schedules))
+		for i, sch := range l.schedules {
+			s[i] = sch.active(t)
+		}
+		return s
+	}
+	current := state(now)
+	t := now.Truncate(time.Minute)
+	for i := 0; i < 8*24*60; i++ {
+		t = t.Add(time.Minute)
+		for j, active := range state(t) {
+			if active != current[j] {
+				return t
+			}
+		}
+	}
+	return time.Time{}
+}
+
+// scheduleOverlays 在下一次覆盖文件生效或失效时重载，加载器关闭后停止
+func (l *Loader) scheduleOverlays() {
+	now := l.cfg.Clock.Now()
+	next := l.nextTransition(now)
+	if next.IsZero() {
+		return
+	}
+	l.cfg.Clock.AfterFunc(next.Sub(now), func() {
+		if l.isClosed() {
+			return
+		}
+		l.logger.Printf("Scheduled overlay switch, reloading")
+		l.reloadMu.Lock()
+		_, err := l.reload(0, l.cfg.Clock.Now())
+		l.reloadMu.Unlock()
+		if err != nil && err != ErrFrozen {
+			l.logger.Printf("Scheduled reload failed: %v", err)
+		}
+		l.scheduleOverlays()
+	})
+}
@@ -0,0 +1,201 @@
+package loadenv
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"maps"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// parserFixtures testdata/parser 中每个文件的期望结果，wantErr 非空时期望 ParseError 的 Msg
+var parserFixtures = map[string]struct {
+	want    map[string]string
+	wantErr string
+}{
+	"basic.env": {want: map[string]string{
+		"APP_NAME": "demo", "PORT": "8080", "EMPTY": "", "COMMENT_ONLY": "", "HASH_IN_VALUE": "a#b",
+	}},
+	"bom_crlf.env": {want: map[string]string{"BOM": "1", "CRLF": "2"}},
+	"expansion.env": {want: map[string]string{
+		"HOST": "db", "PORT": "5432", "DSN": "postgres://db:5432/app", "QUOTED": "db-x",
+		"MISSING": "", "LONE": "$", "OPEN": "${",
+	}},
+	"invalid_key.env":            {wantErr: `invalid key "1KEY"`},
+	"invalid_missing_equals.env": {wantErr: `missing '=' in "NO_EQUALS"`},
+	"invalid_trailing.env":       {wantErr: "unexpected characters after quoted value"},
+	"invalid_unterminated.env":   {wantErr: `unterminated "-quoted value`},
+	"multiline.env": {want: map[string]string{
+		"PEM":  "-----BEGIN KEY-----\nline1\nline2\n-----END KEY-----",
+		"JSON": "{\n  \"a\": 1\n}",
+	}},
+	"quotes.env": {want: map[string]string{
+		"DOUBLE": "tab\tnewline\nquote\"dollar$", "SINGLE": `raw \n $PORT`, "TRAILING": "value",
+	}},
+}
+
+// parserCorpus 返回 testdata/parser 中的所有文件
+func parserCorpus(tb testing.TB) map[string][]byte {
+	tb.Helper()
+	paths, err := filepath.Glob(filepath.Join("testdata", "parser", "*.env"))
+	if err != nil || len(paths) == 0 {
+		tb.Fatalf("no parser fixtures: %v", err)
+	}
+	corpus := make(map[string][]byte, len(paths))
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			tb.Fatal(err)
+		}
+		corpus[filepath.Base(path)] = data
+	}
+	return corpus
+}
+
+func TestParseBytesFixtures(t *testing.T) {
+	for name, src := range parserCorpus(t) {
+		t.Run(name, func(t *testing.T) {
+			tt, ok := parserFixtures[name]
+			if !ok {
+				t.Fatalf("no expected result for fixture %s", name)
+			}
+			env, err := ParseBytes(src)
+			if tt.wantErr != "" {
+				var pe *ParseError
+				if !errors.As(err, &pe) || pe.Msg != tt.wantErr {
+					t.Fatalf("got error %v, want ParseError %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !maps.Equal(env, tt.want) {
+				t.Fatalf("got %q, want %q", env, tt.want)
+			}
+		})
+	}
+}
+
+// FuzzParseBytes 检查任意输入都不会使解析器崩溃，失败时只返回 ParseError，成功时键名合法且结果稳定
+func FuzzParseBytes(f *testing.F) {
+	for _, src := range parserCorpus(f) {
+		f.Add(src)
+	}
+	f.Fuzz(func(t *testing.T, src []byte) {
+		env, err := ParseBytes(src)
+		if err != nil {
+			var pe *ParseError
+			if !errors.As(err, &pe) {
+				t.Fatalf("error %v is not a *ParseError", err)
+			}
+			return
+		}
+		for key := range env {
+			if !validKey(key) {
+				t.Fatalf("invalid key %q in result", key)
+			}
+		}
+		again, err := ParseBytes(src)
+		if err != nil || !maps.Equal(env, again) {
+			t.Fatalf("second parse differs: %q, %v", again, err)
+		}
+	})
+}
+
+// TestLargeValues 超过 1MB 的值经 ParseBytes 和加载器读取后与原值完全一致
+func TestLargeValues(t *testing.T) {
+	const size = 1<<20 + 1
+	fill := func(unit string) string {
+		return strings.Repeat(unit, size/len(unit)+1)
+	}
+	escape := strings.NewReplacer(`\`, `\\`, `"`, `\"`, `$`, `\$`, "\n", `\n`, "\t", `\t`)
+
+	single := fill("eyJhbGciOiJSUzI1NiJ9.")
+	multiline := fill("-----line of a PEM block-----\n")
+	escaped := fill("{\"path\": \"C:\\tmp\", \"cost\": \"$5\"}\n\t")
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{"single line", "KEY=" + single + "\n", single},
+		{"quoted multiline", "KEY=\"" + multiline + "\"\n", multiline},
+		{"escaped", "KEY=\"" + escape.Replace(escaped) + "\"\n", escaped},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := "BEFORE=1\n" + tt.src + "AFTER=2\n"
+			env, err := ParseBytes([]byte(src))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := env["KEY"]; got != tt.want {
+				t.Fatalf("ParseBytes: got %d bytes, want %d bytes (first difference at %d)", len(got), len(tt.want), mismatch(got, tt.want))
+			}
+			if env["BEFORE"] != "1" || env["AFTER"] != "2" {
+				t.Fatalf("neighbouring keys: got BEFORE=%q AFTER=%q", env["BEFORE"], env["AFTER"])
+			}
+
+			path := filepath.Join(t.TempDir(), ".env")
+			if err := os.WriteFile(path, []byte(src), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			l, err := New(Config{FilePath: path, Isolated: true, Logger: log.New(io.Discard, "", 0)})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer l.Close()
+			if got := l.Get("KEY"); got != tt.want {
+				t.Fatalf("Loader: got %d bytes, want %d bytes (first difference at %d)", len(got), len(tt.want), mismatch(got, tt.want))
+			}
+		})
+	}
+}
+
+// mismatch 返回两个字符串第一个不同字节的位置
+func mismatch(a, b string) int {
+	i := 0
+	for i < len(a) && i < len(b) && a[i] == b[i] {
+		i++
+	}
+	return i
+}
+
+// benchmarkEnv 生成包含 n 个键的 .env 内容，混合注释、引号值和变量引用
+func benchmarkEnv(prefix string, n int) []byte {
+	var b strings.Builder
+	for i := 0; i < n; i++ {
+		switch i % 4 {
+		case 0:
+			fmt.Fprintf(&b, "# comment %d\n%s_%d=plain-value-%d\n", i, prefix, i, i)
+		case 1:
+			fmt.Fprintf(&b, "%s_%d=\"quoted \\\"value\\\" %d\\n\"\n", prefix, i, i)
+		case 2:
+			fmt.Fprintf(&b, "%s_%d='single %d'\n", prefix, i, i)
+		default:
+			fmt.Fprintf(&b, "export %s_%d=${%s_%d}/suffix # trailing\n", prefix, i, prefix, i-3)
+		}
+	}
+	return []byte(b.String())
+}
+
+func BenchmarkParseBytes(b *testing.B) {
+	for _, n := range []int{10, 1000} {
+		src := benchmarkEnv("BENCH", n)
+		b.Run(strconv.Itoa(n), func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(int64(len(src)))
+			for i := 0; i < b.N; i++ {
+				if _, err := ParseBytes(src); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
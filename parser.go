@@ -0,0 +1,369 @@
+package loadenv
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// ParseError 解析错误，包含出错的行号（从 1 开始）
+type ParseError struct {
+	Line int
+	Msg  string
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("loadenv: line %d: %s", e.Line, e.Msg)
+}
+
+// ParseBytes 解析 .env 格式的内容，返回键值对
+//
+// 这是一个纯函数：不读取也不修改进程环境变量，可安全地并发调用和用于模糊测试。
+// 支持的语法：
+//
+//	# 注释
+//	export KEY=value         可选的 export 前缀
+//	KEY=value # 行内注释     未加引号的值中，空白后的 # 开始注释
+//	KEY="a\nb ${OTHER}"      双引号：支持转义（\n \r \t \" \\ \$）和变量引用，可跨行
+//	KEY='literal $X'         单引号：原样保留，可跨行
+//	KEY=part1\              行尾的反斜杠表示续行（未加引号或双引号中）
+//	    part2
+//
+// 变量引用（$KEY 或 ${KEY}）只解析为同一输入中先前定义的键，未定义时替换为空字符串
+func ParseBytes(src []byte) (map[string]string, error) {
+	return parse(src, nil, ParseOptions{})
+}
+
+// parse 按 opts 解析内容，lookup 用于解析输入中未定义的变量引用，可为 nil
+func parse(src []byte, lookup func(string) (string, bool), opts ParseOptions) (map[string]string, error) {
+	p := newParser(src, lookup)
+	p.opts = opts
+	if err := p.run(); err != nil {
+		return nil, err
+	}
+	return p.env, nil
+}
+
+// newParser 创建解析器，去掉 BOM 并统一换行符
+func newParser(src []byte, lookup func(string) (string, bool)) *parser {
+	return &parser{
+		src:    strings.ReplaceAll(strings.TrimPrefix(string(src), "\ufeff"), "\r\n", "\n"),
+		line:   1,
+		env:    make(map[string]string, bytes.Count(src, []byte{'\n'})+1),
+		lookup: lookup,
+	}
+}
+
+type parser struct {
+	src    string
+	pos    int
+	line   int
+	env    map[string]string
+	lookup func(string) (string, bool)
+	opts   ParseOptions
+	spans  map[string][][2]int // 每个键的各次定义在 src 中的字节范围（整行，含换行），为 nil 时不记录
+	notes  map[string][]string // 紧挨在每个键最后一次定义上方的注释行（去掉 #），为 nil 时不记录
+	note   []string            // 尚未归属到键的连续注释行
+}
+
+func (p *parser) errorf(line int, format string, args ...any) error {
+	return &ParseError{Line: line, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (p *parser) eof() bool { return p.pos >= len(p.src) }
+
+func (p *parser) peek() byte { return p.src[p.pos] }
+
+// next 前进一个字节并维护行号
+func (p *parser) next() byte {
+	c := p.src[p.pos]
+	p.pos++
+	if c == '\n' {
+		p.line++
+	}
+	return c
+}
+
+// skipBlank 跳过空格和制表符，返回是否跳过了字符
+func (p *parser) skipBlank() bool {
+	start := p.pos
+	for !p.eof() && (p.peek() == ' ' || p.peek() == '\t') {
+		p.pos++
+	}
+	return p.pos > start
+}
+
+// skipLine 跳到下一行的开头
+func (p *parser) skipLine() {
+	for !p.eof() && p.next() != '\n' {
+	}
+}
+
+func (p *parser) run() error {
+	if p.opts.DockerEnvFile {
+		return p.runDocker()
+	}
+	for {
+		// 跳过空行，注释之后的空行使注释不再属于下一个键
+		for !p.eof() && strings.IndexByte(" \t\n", p.peek()) >= 0 {
+			if p.next() == '\n' {
+				p.note = nil
+			}
+		}
+		if p.eof() {
+			return nil
+		}
+		if p.peek() == '#' {
+			start := p.pos
+			p.skipLine()
+			if p.notes != nil {
+				p.note = append(p.note, strings.TrimSpace(strings.TrimPrefix(p.src[start:p.pos], "#")))
+			}
+			continue
+		}
+		if err := p.entry(); err != nil {
+			return err
+		}
+	}
+}
+
+// entry 解析一条 KEY=VALUE
+func (p *parser) entry() error {
+	line := p.line
+	start := strings.LastIndexByte(p.src[:p.pos], '\n') + 1
+
+	eq := strings.IndexAny(p.src[p.pos:], "=\n")
+	if eq < 0 || p.src[p.pos+eq] != '=' {
+		return p.errorf(line, "missing '=' in %q", firstLine(p.src[p.pos:]))
+	}
+	key := strings.TrimSpace(p.src[p.pos : p.pos+eq])
+	p.pos += eq + 1
+	if rest, ok := strings.CutPrefix(key, "export "); ok {
+		key = strings.TrimSpace(rest)
+	}
+	if !validKey(key) {
+		return p.errorf(line, "invalid key %q", key)
+	}
+
+	ws := p.pos
+	blank := p.skipBlank()
+	leading := p.src[ws:p.pos]
+
+	var value string
+	if !p.opts.KeepQuotes && !p.eof() && (p.peek() == '"' || p.peek() == '\'') {
+		v, err := p.quoted()
+		if err != nil {
+			return err
+		}
+		value = v
+	} else {
+		raw := p.unquotedLine()
+		comment := false
+		if blank && strings.HasPrefix(raw, "#") {
+			raw, comment = "", true
+		}
+		if i := inlineComment(raw); i >= 0 {
+			raw, comment = raw[:i], true
+		}
+		if comment || p.opts.Trim != TrimNone {
+			raw = strings.TrimRight(raw, " \t")
+		}
+		if p.opts.Trim == TrimNone && (raw != "" || !comment) {
+			raw = leading + raw
+		}
+		value = p.expand(raw, false)
+	}
+
+	p.env[key] = p.opts.normalize(value)
+	if p.notes != nil {
+		p.notes[key], p.note = p.note, nil
+	}
+	if p.spans != nil {
+		end := p.pos
+		// 引号值之后的行尾可能已被消费
+		if p.src[end-1] != '\n' && !p.eof() && p.peek() == '\n' {
+			end++
+		}
+		p.spans[key] = append(p.spans[key], [2]int{start, end})
+	}
+	return nil
+}
+
+// unquotedLine 读取未加引号的值直到行尾
+//
+// 以奇数个反斜杠结尾的行视为续行：去掉末尾的反斜杠和换行，与下一行拼接。
+// 值的长度不受限制，可以容纳 JWT 公钥、JSON 等很长的内容
+func (p *parser) unquotedLine() string {
+	var b strings.Builder
+	for {
+		end := strings.IndexByte(p.src[p.pos:], '\n')
+		if end < 0 {
+			end = len(p.src) - p.pos
+		}
+		seg := p.src[p.pos : p.pos+end]
+		p.pos += end
+		if !continued(seg) || p.eof() {
+			if b.Len() == 0 {
+				return seg
+			}
+			b.WriteString(seg)
+			return b.String()
+		}
+		b.WriteString(seg[:len(seg)-1])
+		p.next()
+	}
+}
+
+// continued 判断行是否以未转义的反斜杠结尾
+func continued(s string) bool {
+	n := 0
+	for n < len(s) && s[len(s)-1-n] == '\\' {
+		n++
+	}
+	return n%2 == 1
+}
+
+// quoted 解析引号包裹的值，包括引号之后的行尾
+func (p *parser) quoted() (string, error) {
+	line := p.line
+	q := p.next()
+
+	var b strings.Builder
+	for {
+		if p.eof() {
+			return "", p.errorf(line, "unterminated %c-quoted value", q)
+		}
+		c := p.next()
+		if c == q {
+			break
+		}
+		if c == '\\' && q == '"' && !p.eof() {
+			b.WriteByte(c)
+			c = p.next()
+		}
+		b.WriteByte(c)
+	}
+
+	value := b.String()
+	if q == '"' {
+		value = p.expand(value, true)
+	}
+
+	// 引号之后只允许空白和注释
+	p.skipBlank()
+	if !p.eof() && p.peek() != '\n' {
+		if p.peek() != '#' {
+			return "", p.errorf(p.line, "unexpected characters after quoted value")
+		}
+		p.skipLine()
+	}
+	return value, nil
+}
+
+// expand 处理转义（仅双引号）和变量引用
+func (p *parser) expand(s string, escapes bool) string {
+	if !strings.ContainsAny(s, `\$`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '\\' && escapes && i+1 < len(s):
+			i++
+			switch s[i] {
+			case '\n':
+				// 反斜杠加换行为续行，两者都去掉
+			case 'n':
+				b.WriteByte('\n')
+			case 'r':
+				b.WriteByte('\r')
+			case 't':
+				b.WriteByte('\t')
+			case '"', '\\', '$':
+				b.WriteByte(s[i])
+			default:
+				b.WriteByte('\\')
+				b.WriteByte(s[i])
+			}
+		case c == '$':
+			name, n := refName(s[i+1:])
+			if n == 0 {
+				b.WriteByte(c)
+				continue
+			}
+			b.WriteString(p.resolve(name))
+			i += n
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// resolve 查找变量引用的值
+func (p *parser) resolve(name string) string {
+	if v, ok := p.env[name]; ok {
+		return v
+	}
+	if p.lookup != nil {
+		if v, ok := p.lookup(name); ok {
+			return v
+		}
+	}
+	return ""
+}
+
+// refName 解析 $ 之后的变量名，返回变量名和消耗的字节数（不含 $）
+func refName(s string) (string, int) {
+	if strings.HasPrefix(s, "{") {
+		end := strings.IndexByte(s, '}')
+		if end < 0 || !validKey(s[1:end]) {
+			return "", 0
+		}
+		return s[1:end], end + 1
+	}
+	n := 0
+	for n < len(s) && (isKeyStart(s[n]) || (n > 0 && isDigit(s[n]))) {
+		n++
+	}
+	return s[:n], n
+}
+
+// inlineComment 返回未加引号值中行内注释的起始位置，没有时返回 -1
+func inlineComment(s string) int {
+	for i := 1; i < len(s); i++ {
+		if s[i] == '#' && (s[i-1] == ' ' || s[i-1] == '\t') {
+			return i
+		}
+	}
+	return -1
+}
+
+// validKey 判断键名是否合法：字母或下划线开头，后续为字母、数字、下划线、点或连字符
+func validKey(key string) bool {
+	if key == "" || !isKeyStart(key[0]) {
+		return false
+	}
+	for i := 1; i < len(key); i++ {
+		c := key[i]
+		if !isKeyStart(c) && !isDigit(c) && c != '.' && c != '-' {
+			return false
+		}
+	}
+	return true
+}
+
+func isKeyStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isDigit(c byte) bool { return c >= '0' && c <= '9' }
+
+// firstLine 返回 s 的第一行，用于错误信息
+func firstLine(s string) string {
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		return s[:i]
+	}
+	return s
+}
@@ -0,0 +1,113 @@
+package loadenv
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// HumanBoolValues 人工编辑的文件中常见的布尔写法，可直接用作 Config.BoolValues
+var HumanBoolValues = map[string]bool{
+	"yes": true, "no": false,
+	"y": true, "n": false,
+	"on": true, "off": false,
+	"enable": true, "disable": false,
+	"enabled": true, "disabled": false,
+}
+
+// valueParser 按 Config.BoolValues 和 Config.DigitSeparators 解析布尔值和整数；为 nil 时与 strconv 相同
+type valueParser struct {
+	bools      map[string]bool // 额外接受的布尔写法，键为小写
+	separators string          // 整数中允许的数字分隔符
+}
+
+// newValueParser 创建解析器，两项都未配置时返回 nil
+func newValueParser(bools map[string]bool, separators string) (*valueParser, error) {
+	if len(bools) == 0 && separators == "" {
+		return nil, nil
+	}
+	if strings.ContainsAny(separators, "0123456789+-") {
+		return nil, fmt.Errorf("loadenv: invalid digit separators %q", separators)
+	}
+	p := &valueParser{bools: make(map[string]bool, len(bools)), separators: separators}
+	for s, b := range bools {
+		p.bools[strings.ToLower(strings.TrimSpace(s))] = b
+	}
+	return p, nil
+}
+
+// parseBool 先按 strconv.ParseBool 解析，再查找额外的写法（不区分大小写）
+func (p *valueParser) parseBool(s string) (bool, error) {
+	b, err := strconv.ParseBool(s)
+	if err == nil || p == nil {
+		return b, err
+	}
+	if b, ok := p.bools[strings.ToLower(s)]; ok {
+		return b, nil
+	}
+	return false, err
+}
+
+// parseInt 同 strconv.ParseInt，允许数字分隔符
+func (p *valueParser) parseInt(s string, base, bits int) (int64, error) {
+	return strconv.ParseInt(p.digits(s), base, bits)
+}
+
+// parseUint 同 strconv.ParseUint，允许数字分隔符
+func (p *valueParser) parseUint(s string, base, bits int) (uint64, error) {
+	return strconv.ParseUint(p.digits(s), base, bits)
+}
+
+// digits 去掉十进制整数中的数字分隔符
+//
+// 分隔符必须位于两个数字之间；_ 以外的分隔符（如 , 和 .）还必须按三位分组，
+// 避免把 1,5 这样的小数误读为 15。不符合时原样返回，由 strconv 报告错误
+func (p *valueParser) digits(s string) string {
+	if p == nil || p.separators == "" || !strings.ContainsAny(s, p.separators) {
+		return s
+	}
+	num := strings.TrimLeft(s, "+-")
+	var b strings.Builder
+	b.WriteString(s[:len(s)-len(num)])
+	group, grouped := 0, false
+	for i := 0; i < len(num); i++ {
+		c := num[i]
+		switch {
+		case isDigit(c):
+			b.WriteByte(c)
+			group++
+		case strings.IndexByte(p.separators, c) >= 0:
+			if i == 0 || i == len(num)-1 || !isDigit(num[i-1]) || !isDigit(num[i+1]) {
+				return s
+			}
+			if c != '_' {
+				if grouped && group != 3 || !grouped && group > 3 {
+					return s
+				}
+				grouped, group = true, 0
+			}
+		default:
+			return s
+		}
+	}
+	if grouped && group != 3 {
+		return s
+	}
+	return b.String()
+}
+
+// format 返回注解类型 typ 在解析器选项下的格式，与 MetaTypes 中的不同时 ok 为 true
+func (p *valueParser) format(typ string) (f Format, ok bool) {
+	if p == nil {
+		return Format{}, false
+	}
+	switch typ {
+	case "bool":
+		return Format{Name: MetaTypes[typ].Name, Check: func(s string) bool { _, err := p.parseBool(s); return err == nil }}, true
+	case "int":
+		return Format{Name: MetaTypes[typ].Name, Check: func(s string) bool { _, err := p.parseInt(s, 10, 64); return err == nil }}, true
+	case "uint":
+		return Format{Name: MetaTypes[typ].Name, Check: func(s string) bool { _, err := p.parseUint(s, 10, 64); return err == nil }}, true
+	}
+	return Format{}, false
+}
@@ -0,0 +1,48 @@
+package loadenv
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultPodInfoDir Downward API 卷的默认挂载目录
+const DefaultPodInfoDir = "/etc/podinfo"
+
+// podInfoFiles Downward API 文件名与注入的键
+var podInfoFiles = []struct{ file, key string }{
+	{"name", "POD_NAME"},
+	{"namespace", "POD_NAMESPACE"},
+	{"uid", "POD_UID"},
+	{"nodeName", "NODE_NAME"},
+	{"podIP", "POD_IP"},
+}
+
+// podMetadata 读取 Pod 元数据
+//
+// 优先读取 dir 下的 Downward API 文件（name、namespace、uid、nodeName、podIP）；
+// 缺失时 POD_NAME 回退为主机名，POD_NAMESPACE 回退为 ServiceAccount 所在命名空间
+func podMetadata(fsys FS, dir string) map[string]string {
+	if dir == "" {
+		dir = DefaultPodInfoDir
+	}
+	env := make(map[string]string)
+	for _, f := range podInfoFiles {
+		if b, err := fs.ReadFile(fsys, filepath.Join(dir, f.file)); err == nil {
+			env[f.key] = strings.TrimSpace(string(b))
+		}
+	}
+
+	if _, ok := env["POD_NAME"]; !ok {
+		if host, err := os.Hostname(); err == nil {
+			env["POD_NAME"] = host
+		}
+	}
+	if _, ok := env["POD_NAMESPACE"]; !ok {
+		if b, err := fs.ReadFile(fsys, serviceAccountDir+"/namespace"); err == nil {
+			env["POD_NAMESPACE"] = strings.TrimSpace(string(b))
+		}
+	}
+	return env
+}
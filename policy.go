@@ -0,0 +1,49 @@
+package loadenv
+
+import "fmt"
+
+// Policy 配置准入策略，在首次加载和每次重载应用之前以合并后的完整配置求值
+//
+// Check 可以包装任意策略引擎，例如 CEL：
+//
+//	ast, _ := celEnv.Compile(`!(env.APP_ENV == "production" && env.DEBUG == "true")`)
+//	prg, _ := celEnv.Program(ast)
+//	loadenv.Policy{Name: "no-debug-in-prod", Check: func(env map[string]string) error {
+//		out, _, err := prg.Eval(map[string]any{"env": env})
+//		if err != nil {
+//			return err
+//		}
+//		if out != types.True {
+//			return errors.New("DEBUG must be false when APP_ENV=production")
+//		}
+//		return nil
+//	}}
+//
+// 或 OPA 的 rego.PreparedEvalQuery。策略不通过时加载失败，重载时保留原配置
+type Policy struct {
+	Name  string
+	Check func(env map[string]string) error // env 为只读视图，不应修改
+}
+
+// AdmissionError 配置未通过准入策略
+type AdmissionError struct {
+	Policy string
+	Err    error
+}
+
+func (e *AdmissionError) Error() string {
+	return fmt.Sprintf("loadenv: policy %s rejected config: %v", e.Policy, e.Err)
+}
+
+func (e *AdmissionError) Unwrap() error { return e.Err }
+
+// admit 按顺序对 env 求值所有策略，返回所有失败
+func admit(policies []Policy, env map[string]string) error {
+	var errs []error
+	for _, p := range policies {
+		if err := p.Check(env); err != nil {
+			errs = append(errs, &AdmissionError{Policy: p.Name, Err: err})
+		}
+	}
+	return joinErrors(errs...)
+}
@@ -0,0 +1,45 @@
+package loadenv
+
+import "strings"
+
+// prefersOS 判断键是否适用“真实环境优先”策略且在 New 时已存在于进程环境变量中
+func (l *Loader) prefersOS(key string) bool {
+	if _, ok := l.osEnv[key]; !ok {
+		return false
+	}
+	return l.cfg.PreferOSEnv || matchKey(l.cfg.PreferOSEnvKeys, key)
+}
+
+// preferOS 把 snap 中适用该策略的键恢复为 New 时进程环境变量中的值，调用方需持有 mu
+//
+// 在派生值之后执行，环境文件、来源、覆盖文件和派生值都不能遮蔽这些键
+func (l *Loader) preferOS(snap *Snapshot) {
+	if len(l.osEnv) == 0 {
+		return
+	}
+	for key := range snap.env {
+		if !l.prefersOS(key) {
+			continue
+		}
+		v := l.osEnv[key]
+		snap.env[key] = v
+		snap.origins[key] = SourceOS
+		if defs := l.defs[key]; len(defs) == 0 || defs[len(defs)-1].Source != SourceOS {
+			l.define(key, SourceOS, v)
+		}
+		if snap.preferred == nil {
+			snap.preferred = make(map[string]bool)
+		}
+		snap.preferred[key] = true
+	}
+}
+
+// matchKey 判断键是否匹配 patterns 中的任一项，以 * 结尾的项匹配前缀
+func matchKey(patterns []string, key string) bool {
+	for _, p := range patterns {
+		if p == key || strings.HasSuffix(p, "*") && strings.HasPrefix(key, p[:len(p)-1]) {
+			return true
+		}
+	}
+	return false
+}
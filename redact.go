@@ -0,0 +1,20 @@
+package loadenv
+
+import "strings"
+
+// Redacted 脱敏后显示的占位符
+const Redacted = "***"
+
+// secretMarkers 键名中出现这些片段时视为敏感信息
+var secretMarkers = []string{"SECRET", "PASSWORD", "PASSWD", "TOKEN", "API_KEY", "APIKEY", "PRIVATE_KEY", "ACCESS_KEY", "CREDENTIAL", "AUTH", "DSN"}
+
+// IsSecretKey 根据键名判断值是否可能是敏感信息
+func IsSecretKey(key string) bool {
+	k := strings.ToUpper(key)
+	for _, m := range secretMarkers {
+		if strings.Contains(k, m) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,62 @@
+package reloader
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"net/http"
+
+	"github.com/solorez/loadenv"
+)
+
+// HTTPServer 返回一个在 keys 变化时平滑重启 HTTP 服务的组件
+//
+// build 根据配置构造服务（通常读取 LISTEN_ADDR、证书路径等）；TLSConfig 非空时以 TLS 方式监听。
+// 重启时先停止接受新连接并在 StopTimeout 内等待已有请求完成（超时后强制关闭剩余连接），
+// 再用新配置重新监听；监听失败（如端口被占用）作为启动错误返回
+func HTTPServer(name string, keys []string, build func(env loadenv.ReadOnlyEnv) (*http.Server, error)) Component {
+	var srv *http.Server
+	return Component{
+		Name: name,
+		Keys: keys,
+		Start: func(ctx context.Context, env loadenv.ReadOnlyEnv) error {
+			s, err := build(env)
+			if err != nil {
+				return err
+			}
+			addr := s.Addr
+			if addr == "" {
+				addr = ":http"
+			}
+			var lc net.ListenConfig
+			ln, err := lc.Listen(ctx, "tcp", addr)
+			if err != nil {
+				return err
+			}
+			if s.TLSConfig != nil {
+				ln = tls.NewListener(ln, s.TLSConfig)
+			}
+			srv = s
+			go func() {
+				if err := s.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) && s.ErrorLog != nil {
+					s.ErrorLog.Printf("http: serve %s: %v", addr, err)
+				}
+			}()
+			return nil
+		},
+		Stop: func(ctx context.Context) error {
+			if srv == nil {
+				return nil
+			}
+			s := srv
+			srv = nil
+			if err := s.Shutdown(ctx); err != nil {
+				// 等待超时，强制关闭仍未结束的连接
+				s.Close()
+				return err
+			}
+			return nil
+		},
+	}
+}
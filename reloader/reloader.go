@@ -0,0 +1,178 @@
+// Package reloader 在配置变化时按需重启组件
+//
+// 组件声明自己依赖的键以及 Start/Stop 函数，重载后只有依赖的键发生变化的组件会被停止并用新配置重新启动：
+//
+//	r := reloader.New(l, reloader.Options{StopTimeout: 10 * time.Second})
+//	r.Register(reloader.Component{
+//		Name:  "http",
+//		Keys:  []string{"LISTEN_ADDR", "TLS_*"},
+//		Start: func(ctx context.Context, env loadenv.ReadOnlyEnv) error { ... },
+//		Stop:  func(ctx context.Context) error { ... },
+//	})
+//	err := r.Run(ctx)
+package reloader
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/solorez/loadenv"
+)
+
+// Component 一个依赖配置的组件
+type Component struct {
+	Name string
+	Keys []string // 依赖的键，以 * 结尾时按前缀匹配（如 "TLS_*"）
+
+	// Start 用 env 启动组件，应在组件就绪后返回；ctx 在 StartTimeout 后取消
+	Start func(ctx context.Context, env loadenv.ReadOnlyEnv) error
+	// Stop 停止组件，可为 nil；ctx 在 StopTimeout 后取消
+	Stop func(ctx context.Context) error
+}
+
+// affectedBy 判断变化中是否包含组件依赖的键
+func (c *Component) affectedBy(changes []loadenv.Change) bool {
+	for _, ch := range changes {
+		for _, k := range c.Keys {
+			if prefix, ok := strings.CutSuffix(k, "*"); ok {
+				if strings.HasPrefix(ch.Key, prefix) {
+					return true
+				}
+			} else if ch.Key == k {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Options 重启的超时和日志设置
+type Options struct {
+	StartTimeout time.Duration // 单个组件启动的超时，0 表示不限制
+	StopTimeout  time.Duration // 单个组件停止的超时，0 表示不限制
+	Logger       *log.Logger   // 默认丢弃日志
+}
+
+// Reloader 按注册顺序启动组件，按相反顺序停止组件
+type Reloader struct {
+	l    *loadenv.Loader
+	opts Options
+
+	mu      sync.Mutex
+	comps   []*Component
+	running map[*Component]bool
+}
+
+// New 创建绑定到加载器 l 的 Reloader
+func New(l *loadenv.Loader, opts Options) *Reloader {
+	if opts.Logger == nil {
+		opts.Logger = log.New(io.Discard, "", 0)
+	}
+	return &Reloader{l: l, opts: opts, running: make(map[*Component]bool)}
+}
+
+// Register 注册组件，需在 Run 之前调用
+func (r *Reloader) Register(c Component) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.comps = append(r.comps, &c)
+}
+
+// Run 启动所有组件，并在每次重载后重启受影响的组件，直到 ctx 取消或加载器关闭；
+// 返回前按相反顺序停止所有组件。首次启动失败时停止已启动的组件并返回错误
+func (r *Reloader) Run(ctx context.Context) error {
+	// 先订阅再启动，避免错过启动期间的重载；多次重载合并为一次重启
+	sub := r.l.Subscribe(1, loadenv.Coalesce)
+	defer sub.Close()
+
+	r.mu.Lock()
+	comps := slices.Clone(r.comps)
+	r.mu.Unlock()
+	defer r.stop(comps)
+
+	snap := r.l.Snapshot()
+	for _, c := range comps {
+		if err := r.start(c, snap); err != nil {
+			return err
+		}
+	}
+
+	for {
+		select {
+		case ev, ok := <-sub.C:
+			if !ok {
+				return nil
+			}
+			r.restart(comps, ev)
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// restart 停止受影响的组件（逆序），再用新快照启动它们（顺序）
+func (r *Reloader) restart(comps []*Component, ev loadenv.Event) {
+	var affected []*Component
+	for _, c := range comps {
+		if c.affectedBy(ev.Changes) {
+			affected = append(affected, c)
+		}
+	}
+	if len(affected) == 0 {
+		return
+	}
+	r.stop(affected)
+	for _, c := range affected {
+		if err := r.start(c, ev.Snapshot); err != nil {
+			r.opts.Logger.Printf("Component %s failed to restart: %v", c.Name, err)
+			continue
+		}
+		r.opts.Logger.Printf("Component %s restarted", c.Name)
+	}
+}
+
+// start 在超时内启动组件
+func (r *Reloader) start(c *Component, env loadenv.ReadOnlyEnv) error {
+	ctx, cancel := withTimeout(r.opts.StartTimeout)
+	defer cancel()
+	if err := c.Start(ctx, env); err != nil {
+		return fmt.Errorf("reloader: start %s: %w", c.Name, err)
+	}
+	r.mu.Lock()
+	r.running[c] = true
+	r.mu.Unlock()
+	return nil
+}
+
+// stop 按相反顺序停止正在运行的组件，单个组件停止失败不影响其余组件
+func (r *Reloader) stop(comps []*Component) {
+	for i := len(comps) - 1; i >= 0; i-- {
+		c := comps[i]
+		r.mu.Lock()
+		running := r.running[c]
+		delete(r.running, c)
+		r.mu.Unlock()
+		if !running || c.Stop == nil {
+			continue
+		}
+		ctx, cancel := withTimeout(r.opts.StopTimeout)
+		if err := c.Stop(ctx); err != nil {
+			r.opts.Logger.Printf("Component %s failed to stop: %v", c.Name, err)
+		}
+		cancel()
+	}
+}
+
+// withTimeout 返回带超时的 context，d 为 0 时不设超时
+func withTimeout(d time.Duration) (context.Context, context.CancelFunc) {
+	if d <= 0 {
+		return context.WithCancel(context.Background())
+	}
+	return context.WithTimeout(context.Background(), d)
+}
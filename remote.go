@@ -0,0 +1,297 @@
+package loadenv
+
+import (
+	"compress/gzip"
+	"context"
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 配置服务协议：
+//
+//	GET <url>                      返回 {"checksum": "...", "env": {...}}，ETag 为校验和
+//	If-None-Match: "<checksum>"    配置未变化时返回 304
+//	?wait=30s                      与 If-None-Match 一起使用时长轮询：等到配置变化或超时（返回 304）
+//	Authorization: Bearer <token>  服务端配置了令牌时必须携带
+//	Accept-Encoding: gzip          响应以 gzip 压缩；客户端还可以通过 RegisterDecompressor 接受其他编码（如代理压缩的 zstd）
+//
+// 响应体按键排序逐个写出，客户端流式解码，几 MB 的配置也不会在任何一端整体缓冲
+//
+// 服务端为 Loader.ConfigHandler（或 loadenv serve），客户端为 RemoteSource
+
+// maxWait 长轮询的最长等待时间
+const maxWait = 5 * time.Minute
+
+// remoteConfig 配置服务的响应体
+type remoteConfig struct {
+	Checksum string            `json:"checksum"`
+	Env      map[string]string `json:"env"`
+}
+
+// ConfigHandler 返回以配置服务协议提供当前配置的 HTTP 处理器，token 非空时要求 Bearer 认证
+//
+// 响应中包含明文的敏感值，只应在受信任的网络中（或经 TLS）提供
+func (l *Loader) ConfigHandler(token string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if token != "" {
+			got, _ := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+				w.Header().Set("WWW-Authenticate", "Bearer")
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+		}
+
+		snap := l.Snapshot()
+		if etag := r.Header.Get("If-None-Match"); etag != "" && etag == quoteETag(snap.Checksum()) {
+			wait, _ := time.ParseDuration(r.URL.Query().Get("wait"))
+			if snap = l.waitChange(r.Context(), snap, min(wait, maxWait)); snap == nil {
+				w.Header().Set("ETag", etag)
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+		}
+
+		w.Header().Set("ETag", quoteETag(snap.Checksum()))
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		w.Header().Add("Vary", "Accept-Encoding")
+		var out io.Writer = w
+		if acceptsGzip(r) {
+			w.Header().Set("Content-Encoding", "gzip")
+			gz := gzip.NewWriter(w)
+			defer gz.Close()
+			out = gz
+		}
+		writeRemoteConfig(out, snap.Checksum(), snap.Map())
+	})
+}
+
+// waitChange 等待配置相对 snap 变化，返回新快照；超时或请求取消时返回 nil
+func (l *Loader) waitChange(ctx context.Context, snap *Snapshot, wait time.Duration) *Snapshot {
+	if wait <= 0 {
+		return nil
+	}
+	sub := l.Subscribe(1, Coalesce)
+	defer sub.Close()
+	// 订阅之前可能已经发生了变化
+	if cur := l.Snapshot(); cur.Checksum() != snap.Checksum() {
+		return cur
+	}
+	expired := make(chan struct{})
+	timer := l.cfg.Clock.AfterFunc(wait, func() { close(expired) })
+	defer timer.Stop()
+	select {
+	case ev, ok := <-sub.C:
+		if ok {
+			return ev.Snapshot
+		}
+	case <-expired:
+	case <-ctx.Done():
+	}
+	return nil
+}
+
+func quoteETag(checksum string) string { return `"` + checksum + `"` }
+
+// ConfigHandler 返回提供默认加载器配置的 HTTP 处理器，参见 Loader.ConfigHandler
+func ConfigHandler(token string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		l := std.Load()
+		if l == nil {
+			http.Error(w, "config not loaded", http.StatusServiceUnavailable)
+			return
+		}
+		l.ConfigHandler(token).ServeHTTP(w, r)
+	})
+}
+
+// RemoteSource 从配置服务（Loader.ConfigHandler 或 loadenv serve）读取配置
+//
+// 变更通过长轮询发现，服务端配置变化后几乎立即生效。
+// 配置服务位于零信任代理之后时，可以同时使用 Bearer 令牌、自定义请求头和双向 TLS；
+// 客户端证书文件变化（如被 cert-manager 轮换）后在下一次握手时自动重新加载
+type RemoteSource struct {
+	URL     string            // 配置服务地址，如 https://config.dev.svc:8080/
+	Token   string            // Bearer 令牌，服务端未要求认证时留空
+	Headers map[string]string // 每个请求附加的请求头，例如代理要求的身份头
+	Wait    time.Duration     // 每次长轮询的等待时间，默认 30 秒
+
+	// Client 发送请求的客户端（代理、CA、超时等），默认 http.DefaultClient；
+	// 设置 Client.Timeout 时应大于 Wait，否则长轮询会被中断
+	Client *http.Client
+
+	CertFile string // 双向 TLS 的客户端证书（PEM），与 KeyFile 一起设置
+	KeyFile  string // 客户端私钥（PEM）
+	CAFile   string // 校验服务端证书的 CA（PEM），默认使用系统根证书
+
+	clientOnce sync.Once
+	httpClient *http.Client
+	clientErr  error
+
+	mu       sync.Mutex
+	checksum string // 最近一次读到的配置校验和，Load 和 Watch 共用
+}
+
+var _ WatchableSource = (*RemoteSource)(nil)
+
+// Name 返回来源名称
+func (s *RemoteSource) Name() string { return "remote:" + s.URL }
+
+// Load 读取全部配置
+func (s *RemoteSource) Load(ctx context.Context) (map[string]string, error) {
+	cfg, err := s.fetch(ctx, "", 0)
+	if err != nil {
+		return nil, err
+	}
+	s.seen(cfg.Checksum)
+	return cfg.Env, nil
+}
+
+// seen 记录最近读到的配置校验和，返回之前记录的值
+func (s *RemoteSource) seen(checksum string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	prev := s.checksum
+	s.checksum = checksum
+	return prev
+}
+
+// lastChecksum 返回最近读到的配置校验和
+func (s *RemoteSource) lastChecksum() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.checksum
+}
+
+// fetch 请求配置；etag 非空且配置未变化（或长轮询超时）时返回 nil
+func (s *RemoteSource) fetch(ctx context.Context, etag string, wait time.Duration) (*remoteConfig, error) {
+	u := s.URL
+	if wait > 0 {
+		sep := "?"
+		if strings.Contains(u, "?") {
+			sep = "&"
+		}
+		u += sep + "wait=" + wait.String()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range s.Headers {
+		req.Header.Set(k, v)
+	}
+	if s.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.Token)
+	}
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	req.Header.Set("Accept", "application/json")
+	// 显式设置后 http.Transport 不再自动解压，由 decompress 处理
+	req.Header.Set("Accept-Encoding", acceptEncoding())
+
+	client, err := s.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotModified {
+		return nil, nil
+	}
+	if resp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("GET %s: %s: %s", req.URL.Path, resp.Status, strings.TrimSpace(string(body)))
+	}
+	body, err := decompress(resp)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	return readRemoteConfig(body)
+}
+
+// Watch 长轮询配置服务，配置变化时调用 notify；请求失败时记录日志并等待一段时间后重试
+//
+// 以 Load 最近读到的版本作为起点，Load 与第一次轮询之间发布的变更同样会触发 notify
+func (s *RemoteSource) Watch(ctx context.Context, notify func()) error {
+	wait := s.Wait
+	if wait <= 0 {
+		wait = 30 * time.Second
+	}
+	const retry = 5 * time.Second
+	logger, clock := sourceLogger(ctx), sourceClock(ctx)
+	for ctx.Err() == nil {
+		etag := ""
+		if sum := s.lastChecksum(); sum != "" {
+			etag = quoteETag(sum)
+		}
+		cfg, err := s.fetch(ctx, etag, wait)
+		switch {
+		case err != nil:
+			if ctx.Err() != nil {
+				return nil
+			}
+			logger.Printf("Source %s: %v (retrying in %s)", s.Name(), err, retry)
+			sleep(ctx, clock, retry)
+		case cfg != nil:
+			if prev := s.seen(cfg.Checksum); prev != cfg.Checksum {
+				notify()
+			}
+		}
+	}
+	return nil
+}
+
+// client 返回发送请求使用的 http.Client，配置了证书或 CA 时在 Client 的基础上单独创建传输层，
+// 证书和 CA 文件通过 ctx 携带的加载器的 Config.FS 读取
+func (s *RemoteSource) client(ctx context.Context) (*http.Client, error) {
+	s.clientOnce.Do(func() {
+		if s.CertFile == "" && s.CAFile == "" {
+			s.httpClient = httpClient(s.Client)
+			return
+		}
+		l := sourceLoader(ctx)
+		var roots *x509.CertPool
+		if s.CAFile != "" {
+			pem, err := fs.ReadFile(sourceFS(ctx), s.CAFile)
+			if err != nil {
+				s.clientErr = err
+				return
+			}
+			roots = x509.NewCertPool()
+			if !roots.AppendCertsFromPEM(pem) {
+				s.clientErr = fmt.Errorf("loadenv: no certificates found in %s", s.CAFile)
+				return
+			}
+		}
+		w := &tlsWatcher{certKey: "CertFile", keyKey: "KeyFile"}
+		s.httpClient, s.clientErr = tlsClient(s.Client, func(c *tls.Config) {
+			if roots != nil {
+				c.RootCAs = roots
+			}
+			if s.CertFile != "" {
+				c.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+					return w.current(l, s.CertFile, s.KeyFile)
+				}
+			}
+		})
+	})
+	return s.httpClient, s.clientErr
+}
@@ -0,0 +1,54 @@
+package loadenv
+
+import (
+	"context"
+	"io"
+	"log"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestRemoteSourceWatchSeesChangeAfterLoad Load 与第一次长轮询之间发布的变更触发 notify
+func TestRemoteSourceWatchSeesChangeAfterLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+	write := func(data string) {
+		if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("REMOTE_A=1\n")
+	server, err := New(Config{FilePath: path, Isolated: true, Logger: log.New(io.Discard, "", 0)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+	ts := httptest.NewServer(server.ConfigHandler(""))
+	defer ts.Close()
+
+	src := &RemoteSource{URL: ts.URL, Wait: 2 * time.Second}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if env, err := src.Load(ctx); err != nil || env["REMOTE_A"] != "1" {
+		t.Fatalf("Load: got %v, %v", env, err)
+	}
+
+	write("REMOTE_A=2\n")
+	if _, err := server.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	notified := make(chan struct{}, 1)
+	go src.Watch(ctx, func() {
+		select {
+		case notified <- struct{}{}:
+		default:
+		}
+	})
+	select {
+	case <-notified:
+	case <-time.After(time.Second):
+		t.Fatal("change published before Watch started was not reported")
+	}
+}
@@ -0,0 +1,111 @@
+package loadenv
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// RenderTarget 渲染目标：每次加载后用当前环境变量渲染模板并原子替换输出文件
+//
+// 模板使用 text/template 语法，数据为环境变量 map，例如 {{.DB_HOST}}；
+// 另提供 env 函数按键取值：{{env "DB_HOST"}}，以及 default 函数：{{env "PORT" | default "8080"}}
+type RenderTarget struct {
+	Template string      // 模板文件路径
+	Output   string      // 输出文件路径
+	Mode     os.FileMode // 输出文件权限，默认 0644
+}
+
+// renderFuncs 返回模板函数，env 从 data 中取值
+func renderFuncs(data map[string]string) template.FuncMap {
+	return template.FuncMap{
+		"env": func(key string) string { return data[key] },
+		"default": func(def, value string) string {
+			if value == "" {
+				return def
+			}
+			return value
+		},
+	}
+}
+
+// renderAll 用 snap 渲染所有目标，单个目标失败不影响其余目标
+func (l *Loader) renderAll(snap *Snapshot) {
+	targets := l.cfg.Render
+	if len(targets) == 0 {
+		return
+	}
+	data := snap.Map()
+	if !l.cfg.Isolated {
+		data = Merge(environMap(), data)
+	}
+	for _, t := range targets {
+		if err := render(t, data); err != nil {
+			l.logger.Printf("Render %s failed: %v", t.Output, err)
+			continue
+		}
+		l.logger.Printf("Rendered %s from %s", t.Output, t.Template)
+	}
+}
+
+// render 渲染单个目标，先写入同目录临时文件再重命名，保证读者不会看到半写的文件
+func render(t RenderTarget, data map[string]string) error {
+	tmpl, err := template.New(filepath.Base(t.Template)).
+		Funcs(renderFuncs(data)).
+		Option("missingkey=zero").
+		ParseFiles(t.Template)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return err
+	}
+
+	mode := t.Mode
+	if mode == 0 {
+		mode = 0o644
+	}
+	return writeFileAtomic(t.Output, buf.Bytes(), mode)
+}
+
+// writeFileAtomic 原子写文件
+func writeFileAtomic(path string, data []byte, mode os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpName := tmp.Name()
+	defer os.Remove(tmpName)
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(mode); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmpName, path)
+}
+
+// environMap 以 map 形式返回当前进程环境变量
+func environMap() map[string]string {
+	env := make(map[string]string)
+	for _, kv := range os.Environ() {
+		if k, v, ok := strings.Cut(kv, "="); ok {
+			env[k] = v
+		}
+	}
+	return env
+}
@@ -0,0 +1,232 @@
+package loadenv
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"strings"
+)
+
+// ErrUnknownVersion 指定的配置版本不在保留的快照中
+var ErrUnknownVersion = errors.New("loadenv: unknown config version")
+
+// store 将 snap 设为当前快照并按 Config.RetainSnapshots 保留，调用方需持有 mu
+func (l *Loader) store(snap *Snapshot) {
+	l.snapshot.Store(snap)
+	if l.cfg.RetainSnapshots < 0 {
+		return
+	}
+	// 同一版本只保留最新的一份
+	l.history = append(withoutChecksum(l.history, snap.checksum), snap)
+	if n := len(l.history) - l.cfg.RetainSnapshots; n > 0 {
+		clear(l.history[:n])
+		l.history = l.history[n:]
+	}
+}
+
+// withoutChecksum 删除 history 中校验和为 sum 的快照
+func withoutChecksum(history []*Snapshot, sum string) []*Snapshot {
+	out := history[:0]
+	for _, s := range history {
+		if s.checksum != sum {
+			out = append(out, s)
+		}
+	}
+	clear(history[len(out):])
+	return out
+}
+
+// History 返回保留的快照（从新到旧），可用其 Checksum 作为 RollbackTo 和 Pin 的版本
+func (l *Loader) History() []*Snapshot {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	out := make([]*Snapshot, len(l.history))
+	for i, s := range l.history {
+		out[len(out)-1-i] = s
+	}
+	return out
+}
+
+// find 按完整校验和或不短于 12 位的前缀查找保留的快照，调用方需持有 mu
+func (l *Loader) find(version string) (*Snapshot, error) {
+	if len(version) >= 12 {
+		for i := len(l.history) - 1; i >= 0; i-- {
+			if strings.HasPrefix(l.history[i].checksum, version) {
+				return l.history[i], nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnknownVersion, version)
+}
+
+// RollbackTo 将配置恢复为保留的快照中版本为 version 的那份（完整校验和或其前 12 位以上的前缀）
+//
+// 恢复同样会写入进程环境变量、渲染模板、调用钩子并通知订阅者，就像一次正常的重载；
+// 文件或远端来源之后的变更仍会触发重载并覆盖恢复的配置，需要保持时使用 Pin。配置已冻结（参见 Freeze）时返回 ErrFrozen
+func (l *Loader) RollbackTo(version string) (ReloadResult, error) {
+	l.reloadMu.Lock()
+	defer l.reloadMu.Unlock()
+	return l.rollbackTo(version)
+}
+
+// rollbackTo 执行 RollbackTo，调用方需持有 reloadMu
+func (l *Loader) rollbackTo(version string) (ReloadResult, error) {
+	var result ReloadResult
+	if l.frozen.Load() {
+		return result, ErrFrozen
+	}
+	before := l.Snapshot()
+	start := l.cfg.Clock.Now()
+
+	l.mu.Lock()
+	target, err := l.find(version)
+	if err != nil {
+		l.mu.Unlock()
+		return result, err
+	}
+	if target.checksum == before.Checksum() {
+		l.mu.Unlock()
+		return result, nil
+	}
+	applied, snap, err := l.restore(target)
+	l.mu.Unlock()
+	result.Applied = applied
+	if err != nil {
+		l.logger.Printf("Rollback to %s failed: %v", target.ShortChecksum(), err)
+		return result, err
+	}
+	l.logger.Printf("Rolled back config to version %s", snap.ShortChecksum())
+
+	end := l.cfg.Clock.Now()
+	result.Meta = ReloadMeta{
+		Checksum:   snap.Checksum(),
+		DetectedAt: start,
+		AppliedAt:  end,
+		Latency:    end.Sub(start),
+	}
+	result.Changes = l.finish(before, snap, result.Meta)
+	return result, nil
+}
+
+// restore 以 target 的内容生成新的当前快照，调用方需持有 mu
+func (l *Loader) restore(target *Snapshot) ([]string, *Snapshot, error) {
+	env := target.Map()
+	// 派生值和进程原有的变量不写回进程环境变量
+	file := make(map[string]string, len(env))
+	for key, value := range env {
+		if origin := target.origins[key]; origin != SourceDerived && origin != SourceOS {
+			file[key] = value
+		}
+	}
+
+	var applied []string
+	if !l.cfg.Isolated {
+		var err error
+		if applied, err = l.apply(file); err != nil {
+			return applied, nil, err
+		}
+	}
+	snap := &Snapshot{
+		env:      env,
+		origins:  maps.Clone(target.origins),
+		defs:     target.defs,
+		meta:     target.meta,
+		values:   target.values,
+		checksum: target.checksum,
+		onAccess: target.onAccess,
+		isolated: target.isolated,
+		loadedAt: l.cfg.Clock.Now(),
+
+		preferred: target.preferred,
+	}
+	if err := l.seal(snap); err != nil {
+		return applied, nil, err
+	}
+	l.lockSecrets(snap)
+	l.store(snap)
+	if len(l.cfg.Groups) > 0 {
+		l.last = file
+	}
+	if l.pinned != "" {
+		l.pinned = snap.checksum
+	}
+	return applied, snap, nil
+}
+
+// Pin 将配置固定在版本 version：必要时先恢复到该版本（参见 RollbackTo），然后冻结重载直到 Unpin
+//
+// 用于事故期间把整个集群固定在已知可用的配置上，远端来源的变更不会再被应用
+func (l *Loader) Pin(version string) error {
+	l.reloadMu.Lock()
+	defer l.reloadMu.Unlock()
+	if _, err := l.rollbackTo(version); err != nil {
+		return err
+	}
+	l.mu.Lock()
+	l.pinned = l.snapshot.Load().Checksum()
+	l.mu.Unlock()
+	if !l.held.Swap(true) {
+		l.stopDebounce()
+	}
+	return nil
+}
+
+// Unpin 解除 Pin 并立即重载，使文件和来源中的最新配置生效；没有固定版本时不做任何事
+//
+// Unpin 只解除 Pin 本身：配置同时被 Freeze 或 FreezeAfter 冻结时保持冻结，不重载
+func (l *Loader) Unpin() (ReloadResult, error) {
+	l.reloadMu.Lock()
+	defer l.reloadMu.Unlock()
+	l.mu.Lock()
+	pinned := l.pinned
+	l.pinned = ""
+	l.mu.Unlock()
+	if pinned == "" {
+		return ReloadResult{}, nil
+	}
+	l.held.Store(false)
+	l.logger.Printf("Config unpinned from version %s", pinned[:12])
+	if l.frozen.Load() {
+		return ReloadResult{}, nil
+	}
+	return l.reload(0, l.cfg.Clock.Now())
+}
+
+// Pinned 返回 Pin 固定的配置版本，未固定时返回空字符串
+func (l *Loader) Pinned() string {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.pinned
+}
+
+// History 返回默认加载器保留的快照，参见 Loader.History
+func History() []*Snapshot {
+	if l := std.Load(); l != nil {
+		return l.History()
+	}
+	return nil
+}
+
+// RollbackTo 将默认加载器恢复到指定版本，参见 Loader.RollbackTo
+func RollbackTo(version string) (ReloadResult, error) {
+	if l := std.Load(); l != nil {
+		return l.RollbackTo(version)
+	}
+	return ReloadResult{}, fmt.Errorf("%w: %s", ErrUnknownVersion, version)
+}
+
+// Pin 将默认加载器固定在指定版本，参见 Loader.Pin
+func Pin(version string) error {
+	if l := std.Load(); l != nil {
+		return l.Pin(version)
+	}
+	return fmt.Errorf("%w: %s", ErrUnknownVersion, version)
+}
+
+// Unpin 解除默认加载器的 Pin，参见 Loader.Unpin
+func Unpin() (ReloadResult, error) {
+	if l := std.Load(); l != nil {
+		return l.Unpin()
+	}
+	return ReloadResult{}, nil
+}
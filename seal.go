@@ -0,0 +1,66 @@
+package loadenv
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"sync"
+)
+
+// processAEAD 进程级的内存加密密钥，首次使用时随机生成，只存在于本进程内存中
+var processAEAD = sync.OnceValues(func() (cipher.AEAD, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+})
+
+// seal 按 Config.SealSecrets 将快照中敏感键（参见 IsSecretKey）的值加密保存，读取时再解密，调用方需持有 mu
+//
+// 这样内存转储和 core 文件中不会出现这些值的明文。Isolated 模式下效果最完整：
+// 否则进程环境变量中仍保存着明文，这是操作系统层面的限制
+func (l *Loader) seal(snap *Snapshot) error {
+	if !l.cfg.SealSecrets {
+		return nil
+	}
+	aead, err := processAEAD()
+	if err != nil {
+		return err
+	}
+	snap.sealed = make(map[string]bool)
+	for key, value := range snap.env {
+		if !IsSecretKey(key) {
+			continue
+		}
+		nonce := make([]byte, aead.NonceSize())
+		if _, err := rand.Read(nonce); err != nil {
+			return err
+		}
+		// 以键名作为附加数据，密文不能被挪用到其他键上
+		snap.env[key] = string(aead.Seal(nonce, nonce, []byte(value), []byte(key)))
+		snap.sealed[key] = true
+	}
+	return nil
+}
+
+// open 解密键的值，未加密的值原样返回
+func (s *Snapshot) open(key, value string) string {
+	if !s.sealed[key] {
+		return value
+	}
+	aead, err := processAEAD()
+	if err != nil {
+		return ""
+	}
+	n := aead.NonceSize()
+	plain, err := aead.Open(nil, []byte(value[:n]), []byte(value[n:]), []byte(key))
+	if err != nil {
+		return ""
+	}
+	return string(plain)
+}
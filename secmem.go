@@ -0,0 +1,78 @@
+package loadenv
+
+import (
+	"runtime"
+	"sort"
+)
+
+// secureStore 保存在锁定内存中的敏感值，快照不再被引用时清零并释放
+type secureStore struct {
+	mem    []byte
+	spans  map[string][2]int // 键对应的值在 mem 中的起止位置
+	locked bool              // mem 是否成功锁定
+}
+
+// lockedKey 判断键的值是否由锁定内存保存（不写入进程环境变量）
+func (l *Loader) lockedKey(key string) bool {
+	return l.cfg.LockSecrets && IsSecretKey(key)
+}
+
+// lockSecrets 按 Config.LockSecrets 将快照中敏感键的值移入锁定内存，调用方需持有 mu
+//
+// 快照中只保留键名，读取时从锁定内存复制出值。平台不支持或超出 RLIMIT_MEMLOCK 时退化为普通内存并记录日志
+func (l *Loader) lockSecrets(snap *Snapshot) {
+	if !l.cfg.LockSecrets {
+		return
+	}
+	var keys []string
+	size := 0
+	for key, value := range snap.env {
+		if IsSecretKey(key) {
+			keys = append(keys, key)
+			size += len(value)
+		}
+	}
+	if len(keys) == 0 {
+		return
+	}
+	sort.Strings(keys)
+
+	store := &secureStore{spans: make(map[string][2]int, len(keys))}
+	mem, err := lockMemory(size)
+	if err != nil {
+		l.logger.Printf("Secure memory unavailable, keeping secrets in regular memory: %v", err)
+		mem = make([]byte, size)
+	} else {
+		store.locked = true
+	}
+	store.mem = mem
+	off := 0
+	for _, key := range keys {
+		n := copy(mem[off:], snap.env[key])
+		store.spans[key] = [2]int{off, off + n}
+		snap.env[key] = ""
+		off += n
+	}
+	runtime.SetFinalizer(store, (*secureStore).free)
+	snap.secure = store
+}
+
+// free 清零并释放锁定内存
+func (s *secureStore) free() {
+	clear(s.mem)
+	if s.locked {
+		unlockMemory(s.mem)
+	}
+	s.mem = nil
+}
+
+// value 返回键的明文值：依次从锁定内存取出并解密（参见 Config.LockSecrets 和 Config.SealSecrets）
+func (s *Snapshot) value(key, value string) string {
+	if s.secure != nil {
+		if span, ok := s.secure.spans[key]; ok {
+			value = string(s.secure.mem[span[0]:span[1]])
+			runtime.KeepAlive(s.secure)
+		}
+	}
+	return s.open(key, value)
+}
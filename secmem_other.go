@@ -0,0 +1,12 @@
+//go:build !unix
+
+package loadenv
+
+import "errors"
+
+// lockMemory 当前平台不支持锁定内存
+func lockMemory(size int) ([]byte, error) {
+	return nil, errors.New("mlock is not supported on this platform")
+}
+
+func unlockMemory(mem []byte) {}
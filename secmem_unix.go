@@ -0,0 +1,27 @@
+//go:build unix
+
+package loadenv
+
+import "syscall"
+
+// lockMemory 分配 size 字节的匿名内存并用 mlock 锁定，防止被换出到磁盘
+func lockMemory(size int) ([]byte, error) {
+	if size == 0 {
+		size = 1
+	}
+	mem, err := syscall.Mmap(-1, 0, size, syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_ANON|syscall.MAP_PRIVATE)
+	if err != nil {
+		return nil, err
+	}
+	if err := syscall.Mlock(mem); err != nil {
+		syscall.Munmap(mem)
+		return nil, err
+	}
+	return mem[:size], nil
+}
+
+// unlockMemory 解除锁定并释放 lockMemory 分配的内存
+func unlockMemory(mem []byte) {
+	syscall.Munlock(mem)
+	syscall.Munmap(mem)
+}
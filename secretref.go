@@ -0,0 +1,53 @@
+package loadenv
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// ResolveSecretRefs 解析值中的密钥引用，作为 Transformer 使用：
+//
+//	op://vault/item/field   通过 1Password CLI（op read）读取
+//	bws://<secret-id>       通过 Bitwarden Secrets Manager CLI（bws secret get）读取
+//
+// 其他值保持不变。CLI 需要在 PATH 中且已完成登录（例如设置了 OP_SERVICE_ACCOUNT_TOKEN 或 BWS_ACCESS_TOKEN）
+func ResolveSecretRefs(key, value string) (string, error) {
+	switch {
+	case strings.HasPrefix(value, "op://"):
+		out, err := runCLI("op", "read", "--no-newline", value)
+		if err != nil {
+			return "", err
+		}
+		return string(out), nil
+
+	case strings.HasPrefix(value, "bws://"):
+		id := strings.TrimPrefix(value, "bws://")
+		out, err := runCLI("bws", "secret", "get", id, "--output", "json")
+		if err != nil {
+			return "", err
+		}
+		var secret struct {
+			Value string `json:"value"`
+		}
+		if err := json.Unmarshal(out, &secret); err != nil {
+			return "", fmt.Errorf("bws: decode secret %s: %w", id, err)
+		}
+		return secret.Value, nil
+	}
+	return value, nil
+}
+
+// runCLI 执行外部命令并返回标准输出，失败时附带标准错误内容
+func runCLI(name string, args ...string) ([]byte, error) {
+	var stderr bytes.Buffer
+	cmd := exec.Command(name, args...)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w: %s", name, err, strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
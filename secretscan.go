@@ -0,0 +1,136 @@
+package loadenv
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SecretFinding 一个疑似明文凭据
+type SecretFinding struct {
+	Key    string
+	Reason string // 判定依据：键名、已知凭据格式或高熵值
+}
+
+// PolicyError 环境文件违反凭据策略
+type PolicyError struct {
+	File     string
+	Findings []SecretFinding
+}
+
+func (e *PolicyError) Error() string {
+	reasons := make([]string, len(e.Findings))
+	for i, f := range e.Findings {
+		reasons[i] = f.Key + " (" + f.Reason + ")"
+	}
+	return fmt.Sprintf("loadenv: %s contains plaintext credentials, which must come from a secret manager: %s",
+		e.File, strings.Join(reasons, ", "))
+}
+
+// credentialPatterns 常见服务的凭据格式
+var credentialPatterns = []struct {
+	name string
+	re   *regexp.Regexp
+}{
+	{"AWS access key", regexp.MustCompile(`^(AKIA|ASIA)[0-9A-Z]{16}$`)},
+	{"GitHub token", regexp.MustCompile(`^(ghp|gho|ghu|ghs|ghr|github_pat)_[A-Za-z0-9_]{20,}$`)},
+	{"Slack token", regexp.MustCompile(`^xox[abprs]-[A-Za-z0-9-]{10,}$`)},
+	{"Stripe key", regexp.MustCompile(`^(sk|rk)_live_[A-Za-z0-9]{16,}$`)},
+	{"private key", regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----`)},
+	{"JWT", regexp.MustCompile(`^eyJ[A-Za-z0-9_-]+\.eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+$`)},
+}
+
+// 高熵判定的阈值：足够长且每个字符的平均信息量较高的值通常是随机生成的密钥
+const (
+	minEntropyLen = 20
+	minEntropy    = 4.0
+)
+
+// ScanSecrets 检查 env 中疑似明文凭据的值：键名像凭据（参见 IsSecretKey）且值不是数字、布尔值、时长或不含密码的 URL、
+// 值符合已知凭据格式，或值是较长的高熵字符串。op://、bws:// 等密钥引用不计入。结果按键名排序
+func ScanSecrets(env map[string]string) []SecretFinding {
+	var findings []SecretFinding
+	for key, value := range env {
+		if reason := secretReason(key, value); reason != "" {
+			findings = append(findings, SecretFinding{Key: key, Reason: reason})
+		}
+	}
+	sort.Slice(findings, func(i, j int) bool { return findings[i].Key < findings[j].Key })
+	return findings
+}
+
+// secretReason 返回值被判定为凭据的原因，不是凭据时返回空字符串
+func secretReason(key, value string) string {
+	v := strings.TrimSpace(value)
+	if v == "" || isSecretRef(v) || lintValue(key, v) != "" {
+		return ""
+	}
+	for _, p := range credentialPatterns {
+		if p.re.MatchString(v) {
+			return p.name
+		}
+	}
+	if IsSecretKey(key) && !settingValue(v) {
+		return "credential-like key name"
+	}
+	if len(v) >= minEntropyLen && !strings.ContainsAny(v, " /") && shannonEntropy(v) >= minEntropy {
+		return "high-entropy value"
+	}
+	return ""
+}
+
+// settingValue 判断值是否明显是设置而不是凭据：数字、布尔值、时长，或不含密码的 URL，
+// 例如 TOKEN_TTL=3600、AUTH_ENABLED=true、PASSWORD_MIN_LENGTH=8、DSN=postgres://db:5432/app
+func settingValue(v string) bool {
+	if _, err := strconv.ParseFloat(v, 64); err == nil {
+		return true
+	}
+	if _, err := strconv.ParseBool(v); err == nil {
+		return true
+	}
+	if _, err := time.ParseDuration(v); err == nil {
+		return true
+	}
+	if u, err := url.Parse(v); err == nil && u.Scheme != "" && u.Host != "" {
+		_, hasPassword := u.User.Password()
+		return !hasPassword
+	}
+	return false
+}
+
+// checkPlaintext 按 Config.ManagedSecrets 检查明文文件 path 中的值，发现疑似凭据时返回 PolicyError
+func (l *Loader) checkPlaintext(path string, env map[string]string) error {
+	if !l.cfg.ManagedSecrets {
+		return nil
+	}
+	if findings := ScanSecrets(env); len(findings) > 0 {
+		return &PolicyError{File: path, Findings: findings}
+	}
+	return nil
+}
+
+// isSecretRef 判断值是否是指向密钥管理服务的引用，参见 ResolveSecretRefs
+func isSecretRef(v string) bool {
+	return strings.HasPrefix(v, "op://") || strings.HasPrefix(v, "bws://")
+}
+
+// shannonEntropy 返回 s 中每个字符的平均信息量（比特）
+func shannonEntropy(s string) float64 {
+	counts := make(map[rune]int)
+	n := 0
+	for _, r := range s {
+		counts[r]++
+		n++
+	}
+	var h float64
+	for _, c := range counts {
+		p := float64(c) / float64(n)
+		h -= p * math.Log2(p)
+	}
+	return h
+}
@@ -0,0 +1,59 @@
+package loadenv
+
+import (
+	"os"
+	"sort"
+	"strings"
+)
+
+// Shadow 环境文件（或来源）与进程中原有的同名变量值不同，其中一个值被静默忽略
+type Shadow struct {
+	Key       string
+	FileValue string // 环境文件或来源中的值
+	OSValue   string // 进程中原有的值
+	Winner    string // 生效的一方：os 或文件、来源的名称，参见 Snapshot.Source
+}
+
+// shadows 找出 env 中与进程原有变量值不同的键，调用方需持有 mu
+//
+// 由本加载器写入的键不算进程原有的变量
+func (l *Loader) shadows(env map[string]string, origins map[string]string) []Shadow {
+	var ss []Shadow
+	for key, value := range env {
+		if _, owned := l.applied[key]; owned {
+			continue
+		}
+		osv, ok := os.LookupEnv(key)
+		if !ok || osv == value {
+			continue
+		}
+		winner := origins[key]
+		if l.prefersOS(key) {
+			winner = SourceOS
+		}
+		ss = append(ss, Shadow{Key: key, FileValue: value, OSValue: osv, Winner: winner})
+	}
+	sort.Slice(ss, func(i, j int) bool { return ss[i].Key < ss[j].Key })
+	return ss
+}
+
+// reportShadows 记录一条列出所有遮蔽的警告并调用 OnShadow
+func (l *Loader) reportShadows(ss []Shadow) {
+	if len(ss) == 0 {
+		return
+	}
+	parts := make([]string, len(ss))
+	for i, s := range ss {
+		winner := "file wins"
+		if s.Winner == SourceOS {
+			winner = "process environment wins"
+		} else if !strings.HasPrefix(s.Winner, "file:") {
+			winner = s.Winner + " wins"
+		}
+		parts[i] = s.Key + " (" + winner + ")"
+	}
+	l.logger.Printf("Warning: %d keys differ between the config and the existing process environment: %s", len(ss), strings.Join(parts, ", "))
+	if l.cfg.OnShadow != nil {
+		l.cfg.OnShadow(ss)
+	}
+}
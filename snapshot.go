@@ -0,0 +1,153 @@
+package loadenv
+
+import (
+	"os"
+	"sort"
+	"time"
+)
+
+// ReadOnlyEnv 只读的环境变量视图
+type ReadOnlyEnv interface {
+	Get(key string) string
+	Lookup(key string) (string, bool)
+}
+
+// Snapshot 某次加载完成时的配置快照，创建后不再改变
+//
+// 对环境文件中定义的键，快照内的值在多次读取之间保持一致，不受之后的重载影响；
+// 其他键回退到读取时的进程环境变量（Isolated 加载器的快照不回退）
+type Snapshot struct {
+	env      map[string]string
+	origins  map[string]string // 每个键的来源：file:<路径>、来源名称、os、pod 或 derived
+	checksum string
+	onAccess func(key string, found bool)
+	isolated bool                    // 由 Isolated 加载器创建，不回退到进程环境变量
+	loadedAt time.Time               // 快照生成（配置生效）的时间
+	sealed   map[string]bool         // 值经过内存加密的键，参见 Config.SealSecrets
+	secure   *secureStore            // 保存在锁定内存中的值，参见 Config.LockSecrets
+	defs     map[string][]Definition // 每个键在各层中的定义（敏感值已隐藏），参见 Explain
+	meta     map[string]KeyMeta      // 键的注释注解，参见 Describe
+	values   *valueParser            // 布尔值和整数的解析选项，参见 Config.BoolValues
+
+	preferred map[string]bool // 因真实环境优先策略使用进程环境变量的键，参见 Config.PreferOSEnv
+}
+
+var _ ReadOnlyEnv = (*Snapshot)(nil)
+
+// Get 返回键对应的值，不存在时返回空字符串
+func (s *Snapshot) Get(key string) string {
+	v, _ := s.Lookup(key)
+	return v
+}
+
+// Lookup 返回键对应的值以及是否存在
+func (s *Snapshot) Lookup(key string) (string, bool) {
+	v, ok := s.lookup(key)
+	if s != nil && s.onAccess != nil {
+		s.onAccess(key, ok)
+	}
+	return v, ok
+}
+
+func (s *Snapshot) lookup(key string) (string, bool) {
+	if s != nil {
+		if v, ok := s.env[key]; ok {
+			return s.value(key, v), true
+		}
+		if s.isolated {
+			return "", false
+		}
+	}
+	return os.LookupEnv(key)
+}
+
+// Source 返回键的来源：file:<路径>、Source 的名称、os（进程原有变量）、pod 或 derived；
+// 不由加载器管理的键返回空字符串
+func (s *Snapshot) Source(key string) string {
+	if s == nil {
+		return ""
+	}
+	return s.origins[key]
+}
+
+// LoadedAt 返回快照生成（即这份配置生效）的时间
+func (s *Snapshot) LoadedAt() time.Time {
+	if s == nil {
+		return time.Time{}
+	}
+	return s.loadedAt
+}
+
+// Keys 返回快照中由环境文件定义的键（已排序）
+func (s *Snapshot) Keys() []string {
+	if s == nil {
+		return nil
+	}
+	keys := make([]string, 0, len(s.env))
+	for k := range s.env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Map 返回快照中由环境文件定义的键值对副本
+func (s *Snapshot) Map() map[string]string {
+	if s == nil {
+		return map[string]string{}
+	}
+	m := make(map[string]string, len(s.env))
+	for k, v := range s.env {
+		m[k] = s.value(k, v)
+	}
+	return m
+}
+
+// Snapshot 返回最近一次加载的配置快照；Lazy 加载器首次调用时先执行加载
+func (l *Loader) Snapshot() *Snapshot {
+	l.EnsureLoaded()
+	return l.snapshot.Load()
+}
+
+// With 在同一个快照上执行 fn，保证 fn 中读取的多个键来自同一次加载
+func (l *Loader) With(fn func(env ReadOnlyEnv)) {
+	fn(l.Snapshot())
+}
+
+// Get 返回当前配置中键对应的值，不存在时返回空字符串
+func (l *Loader) Get(key string) string {
+	return l.Snapshot().Get(key)
+}
+
+// Lookup 返回当前配置中键对应的值以及是否存在
+func (l *Loader) Lookup(key string) (string, bool) {
+	return l.Snapshot().Lookup(key)
+}
+
+// Get 返回默认加载器中键对应的值，未初始化时读取进程环境变量
+func Get(key string) string {
+	return current().Get(key)
+}
+
+// Lookup 返回默认加载器中键对应的值以及是否存在，未初始化时读取进程环境变量
+func Lookup(key string) (string, bool) {
+	return current().Lookup(key)
+}
+
+// GetSnapshot 返回默认加载器的配置快照，未初始化时返回只包含进程环境变量回退的空快照
+func GetSnapshot() *Snapshot {
+	return current()
+}
+
+// With 在默认加载器的同一个快照上执行 fn
+func With(fn func(env ReadOnlyEnv)) {
+	fn(current())
+}
+
+// current 返回默认加载器的当前快照
+func current() *Snapshot {
+	if l := std.Load(); l != nil {
+		return l.Snapshot()
+	}
+	return nil
+}
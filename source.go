@@ -0,0 +1,269 @@
+package loadenv
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"maps"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Source 环境文件之外的配置来源，按 Config.Sources 中的顺序合并，后面的来源覆盖前面的值
+type Source interface {
+	// Name 返回来源名称，用于日志和错误信息
+	Name() string
+	// Load 读取来源中的全部键值对
+	Load(ctx context.Context) (map[string]string, error)
+}
+
+// WatchableSource 能够感知远端变更的来源
+type WatchableSource interface {
+	Source
+	// Watch 阻塞直到 ctx 取消，远端发生变更时调用 notify
+	Watch(ctx context.Context, notify func()) error
+}
+
+// 特殊的来源名称，用于来源归属
+const (
+	SourceOS      = "os"      // 进程启动前已存在的环境变量，优先于文件和来源
+	SourcePod     = "pod"     // Pod 元数据
+	SourceDerived = "derived" // 通过 RegisterDerived 注册的派生值
+
+	SourceTransform = "transform" // Transformers 改写后的值，只出现在 Explanation.Definitions 中
+)
+
+// loadSources 依次读取所有来源，按 Merge 的规则合并到 env 之上，并在 origins 中记录每个键的来源
+func (l *Loader) loadSources(env, origins map[string]string) (map[string]string, error) {
+	layers := make([]map[string]string, 0, len(l.cfg.Sources))
+	for _, src := range l.cfg.Sources {
+		// 读取时持有 mu，挂起的远端不能无限期阻塞读取方
+		ctx, cancel := context.WithTimeout(withLoader(context.Background(), l), l.cfg.SourceTimeout)
+		m, err := src.Load(ctx)
+		cancel()
+		if err != nil {
+			return nil, &SourceError{Source: src.Name(), Err: err}
+		}
+		for key, value := range m {
+			origins[key] = src.Name()
+			l.define(key, src.Name(), value)
+		}
+		layers = append(layers, m)
+	}
+	return Merge(env, layers...), nil
+}
+
+type loaderKey struct{}
+
+// withLoader 返回携带加载器的 context，来源的 Load 和 Watch 通过它使用加载器的日志记录器、
+// Config.FS 和 Config.Clock，参见 sourceLogger、sourceFS 和 sourceClock
+func withLoader(ctx context.Context, l *Loader) context.Context {
+	return context.WithValue(ctx, loaderKey{}, l)
+}
+
+// sourceLoader 返回 ctx 携带的加载器，没有时（直接调用来源的方法）返回 nil
+func sourceLoader(ctx context.Context) *Loader {
+	l, _ := ctx.Value(loaderKey{}).(*Loader)
+	return l
+}
+
+// sourceLogger 返回加载器的日志记录器，没有加载器时返回 log.Default()
+func sourceLogger(ctx context.Context) *log.Logger {
+	if l := sourceLoader(ctx); l != nil {
+		return l.logger
+	}
+	return log.Default()
+}
+
+// sourceFS 返回加载器的 Config.FS，没有加载器时返回本地文件系统
+func sourceFS(ctx context.Context) FS {
+	if l := sourceLoader(ctx); l != nil {
+		return l.cfg.FS
+	}
+	return osFS{}
+}
+
+// sourceClock 返回加载器的 Config.Clock，没有加载器时返回真实时间
+func sourceClock(ctx context.Context) Clock {
+	if l := sourceLoader(ctx); l != nil {
+		return l.cfg.Clock
+	}
+	return realClock{}
+}
+
+// sleep 按 clock 等待 d，ctx 取消时提前返回 false
+func sleep(ctx context.Context, clock Clock, d time.Duration) bool {
+	done := make(chan struct{})
+	t := clock.AfterFunc(d, func() { close(done) })
+	select {
+	case <-done:
+		return true
+	case <-ctx.Done():
+		t.Stop()
+		return false
+	}
+}
+
+// watchSources 为支持变更通知的来源启动监听，加载器关闭时停止
+func (l *Loader) watchSources() {
+	ctx, cancel := context.WithCancel(withLoader(context.Background(), l))
+	go func() {
+		<-l.closeCh
+		cancel()
+	}()
+
+	for _, src := range l.cfg.Sources {
+		ws, ok := src.(WatchableSource)
+		if !ok {
+			continue
+		}
+		go func() {
+			l.logger.Printf("Watching source: %s", ws.Name())
+			if err := ws.Watch(ctx, l.Notify); err != nil && ctx.Err() == nil {
+				l.logger.Printf("Source %s watch stopped: %v", ws.Name(), err)
+			}
+		}()
+	}
+}
+
+// SourceError 来源读取失败
+type SourceError struct {
+	Source string
+	Err    error
+}
+
+func (e *SourceError) Error() string { return "loadenv: source " + e.Source + ": " + e.Err.Error() }
+
+func (e *SourceError) Unwrap() error { return e.Err }
+
+// poller 远端来源共用的轮询与 webhook 触发逻辑
+type poller struct {
+	once    sync.Once
+	trigger chan struct{}
+}
+
+func (p *poller) triggerCh() chan struct{} {
+	p.once.Do(func() { p.trigger = make(chan struct{}, 1) })
+	return p.trigger
+}
+
+// poke 请求立即检查一次，不阻塞
+func (p *poller) poke() {
+	select {
+	case p.triggerCh() <- struct{}{}:
+	default:
+	}
+}
+
+// watch 每隔 interval 或收到 webhook 时读取一次，内容变化时调用 notify
+//
+// 读取失败（包括第一次）不终止监听：记录日志后等待下一次检查。
+// 第一次读取失败时没有可比较的内容，之后第一次成功读取时调用 notify
+func (p *poller) watch(ctx context.Context, name string, interval time.Duration, load func(context.Context) (map[string]string, error), notify func()) error {
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	logger, clock := sourceLogger(ctx), sourceClock(ctx)
+	last, err := load(ctx)
+	known := err == nil
+	if err != nil && ctx.Err() == nil {
+		logger.Printf("Source %s: %v (retrying in %s)", name, err, interval)
+	}
+
+	for {
+		tick := make(chan struct{})
+		t := clock.AfterFunc(interval, func() { close(tick) })
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return nil
+		case <-tick:
+		case <-p.triggerCh():
+			t.Stop()
+		}
+
+		m, err := load(ctx)
+		if err != nil {
+			if ctx.Err() == nil {
+				logger.Printf("Source %s: %v (retrying in %s)", name, err, interval)
+			}
+			continue
+		}
+		if !known || !maps.Equal(last, m) {
+			last, known = m, true
+			notify()
+		}
+	}
+}
+
+// webhookHandler 返回触发立即检查的 HTTP 处理器，verify 为 nil 时不校验签名
+func (p *poller) webhookHandler(verify func(r *http.Request, body []byte) bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		body, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if verify != nil && !verify(r, body) {
+			http.Error(w, "invalid signature", http.StatusUnauthorized)
+			return
+		}
+		p.poke()
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+// hmacSHA256Hex 计算 HMAC-SHA256 并以十六进制返回
+func hmacSHA256Hex(secret string, data []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(data)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// httpClient 返回来源使用的 HTTP 客户端，未注入时为 http.DefaultClient
+func httpClient(c *http.Client) *http.Client {
+	if c != nil {
+		return c
+	}
+	return http.DefaultClient
+}
+
+// tlsClient 在 base（nil 表示 http.DefaultClient）的基础上返回一个新客户端，其 TLS 设置经 configure 调整；
+// 代理、超时等其他设置保持不变。base 的传输层不是 *http.Transport 时无法注入 TLS 设置，返回错误
+func tlsClient(base *http.Client, configure func(*tls.Config)) (*http.Client, error) {
+	base = httpClient(base)
+	rt := base.Transport
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	t, ok := rt.(*http.Transport)
+	if !ok {
+		return nil, fmt.Errorf("loadenv: cannot configure TLS on transport %T", rt)
+	}
+	t = t.Clone()
+	if t.TLSClientConfig == nil {
+		t.TLSClientConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+	configure(t.TLSClientConfig)
+
+	c := *base
+	c.Transport = t
+	return &c, nil
+}
+
+// WritableSource 支持写入的来源
+type WritableSource interface {
+	Source
+	// Store 新增或更新 env 中的键，不会删除来源中已有的其他键
+	Store(ctx context.Context, env map[string]string) error
+}
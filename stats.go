@@ -0,0 +1,69 @@
+package loadenv
+
+import (
+	"sync"
+	"time"
+)
+
+// Stats 加载器的运行统计，供监督程序判断配置子系统是否退化
+type Stats struct {
+	Reloads         uint64        // 成功的重载次数（不含首次加载）
+	Failures        uint64        // 失败的重载次数
+	LastReload      time.Time     // 最近一次成功重载的完成时间
+	LastDuration    time.Duration // 最近一次重载（无论成败）的耗时
+	LastError       error         // 最近一次失败的错误，之后成功重载不会清除
+	LastErrorAt     time.Time     // LastError 发生的时间
+	WatcherRestarts uint64        // 文件监听器被重建的次数
+	WatcherErrors   uint64        // 文件监听器报告的错误次数
+	LastWatcherErr  error         // 最近一次监听器错误
+}
+
+// stats 加载器内部的统计数据
+type stats struct {
+	mu sync.Mutex
+	s  Stats
+}
+
+// recordReload 记录一次重载的结果
+func (st *stats) recordReload(start, end time.Time, err error) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.s.LastDuration = end.Sub(start)
+	if err != nil {
+		st.s.Failures++
+		st.s.LastError, st.s.LastErrorAt = err, end
+		return
+	}
+	st.s.Reloads++
+	st.s.LastReload = end
+}
+
+// recordWatcherRestart 记录一次监听器重建
+func (st *stats) recordWatcherRestart() {
+	st.mu.Lock()
+	st.s.WatcherRestarts++
+	st.mu.Unlock()
+}
+
+// recordWatcherError 记录一次监听器错误
+func (st *stats) recordWatcherError(err error) {
+	st.mu.Lock()
+	st.s.WatcherErrors++
+	st.s.LastWatcherErr = err
+	st.mu.Unlock()
+}
+
+// Stats 返回加载器当前的运行统计
+func (l *Loader) Stats() Stats {
+	l.stats.mu.Lock()
+	defer l.stats.mu.Unlock()
+	return l.stats.s
+}
+
+// GetStats 返回默认加载器的运行统计，未初始化时返回零值
+func GetStats() Stats {
+	if l := std.Load(); l != nil {
+		return l.Stats()
+	}
+	return Stats{}
+}
@@ -0,0 +1,198 @@
+package loadenv
+
+import (
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+// Event 一次生效的重载，投递给订阅者
+type Event struct {
+	Snapshot *Snapshot  // 重载后的快照
+	Changes  []Change   // 配置的变化；合并投递时为多次重载累计的变化
+	Meta     ReloadMeta // 触发原因和耗时；合并投递时为最近一次重载的信息
+}
+
+// Backpressure 订阅者处理不过来时的投递策略
+type Backpressure int
+
+const (
+	// Block 缓冲区满时阻塞重载，直到订阅者取走事件或取消订阅
+	Block Backpressure = iota
+	// DropOldest 缓冲区满时丢弃最旧的事件，丢弃数量计入 Dropped
+	DropOldest
+	// Coalesce 缓冲区满时将新事件与最新的待处理事件合并，订阅者总能拿到最新快照，合并次数计入 Dropped
+	Coalesce
+)
+
+// Subscription 一个变更订阅，通过 C 接收事件
+type Subscription struct {
+	C <-chan Event
+
+	ch      chan Event
+	policy  Backpressure
+	done    chan struct{}
+	once    sync.Once
+	dropped atomic.Uint64
+	l       *Loader
+}
+
+// Subscribe 订阅重载事件，buffer 为缓冲区大小（小于 1 时按 1 处理）
+//
+// 事件在重载协程中按顺序投递，不会为每个订阅者创建额外的 goroutine；
+// 加载器关闭或调用 Subscription.Close 后 C 被关闭
+func (l *Loader) Subscribe(buffer int, policy Backpressure) *Subscription {
+	if buffer < 1 {
+		buffer = 1
+	}
+	ch := make(chan Event, buffer)
+	s := &Subscription{C: ch, ch: ch, policy: policy, done: make(chan struct{}), l: l}
+
+	l.subsMu.Lock()
+	defer l.subsMu.Unlock()
+	if l.isClosed() {
+		s.once.Do(func() { close(s.done) })
+		close(ch)
+		return s
+	}
+	l.subs = append(l.subs, s)
+	return s
+}
+
+// Dropped 返回因缓冲区满而被丢弃或合并的事件数量
+func (s *Subscription) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+// Close 取消订阅并关闭 C，可重复调用
+func (s *Subscription) Close() {
+	s.once.Do(func() { close(s.done) })
+
+	l := s.l
+	l.subsMu.Lock()
+	defer l.subsMu.Unlock()
+	for i, sub := range l.subs {
+		if sub == s {
+			l.subs = append(l.subs[:i], l.subs[i+1:]...)
+			close(s.ch)
+			break
+		}
+	}
+}
+
+// publish 将事件投递给所有订阅者，调用方需持有 reloadMu
+func (l *Loader) publish(ev Event) {
+	l.subsMu.Lock()
+	defer l.subsMu.Unlock()
+	for _, s := range l.subs {
+		// 每个订阅者拿到独立的切片，互不影响
+		s.send(Event{Snapshot: ev.Snapshot, Changes: slices.Clone(ev.Changes), Meta: ev.Meta}, l.closeCh)
+	}
+}
+
+// send 按订阅者的策略投递事件，调用方需持有 subsMu
+func (s *Subscription) send(ev Event, closeCh <-chan struct{}) {
+	switch s.policy {
+	case DropOldest:
+		for {
+			select {
+			case s.ch <- ev:
+				return
+			default:
+			}
+			select {
+			case <-s.ch:
+				s.dropped.Add(1)
+			default:
+			}
+		}
+	case Coalesce:
+		for {
+			select {
+			case s.ch <- ev:
+				return
+			default:
+			}
+			// 取出最新的待处理事件与当前事件合并，较旧的事件保持原样
+			pending := make([]Event, 0, cap(s.ch))
+		drain:
+			for {
+				select {
+				case p := <-s.ch:
+					pending = append(pending, p)
+				default:
+					break drain
+				}
+			}
+			if n := len(pending); n > 0 {
+				ev = Event{Snapshot: ev.Snapshot, Changes: mergeChanges(pending[n-1].Changes, ev.Changes), Meta: ev.Meta}
+				s.dropped.Add(1)
+				for _, p := range pending[:n-1] {
+					s.ch <- p
+				}
+			}
+		}
+	default:
+		select {
+		case s.ch <- ev:
+		case <-s.done:
+		case <-closeCh:
+		}
+	}
+}
+
+// closeSubs 关闭所有订阅，在加载器关闭时调用
+func (l *Loader) closeSubs() {
+	l.subsMu.Lock()
+	defer l.subsMu.Unlock()
+	for _, s := range l.subs {
+		s.once.Do(func() { close(s.done) })
+		close(s.ch)
+	}
+	l.subs = nil
+}
+
+// mergeChanges 合并两次连续的变化：每个键保留最早的旧值和最新的新值，最终没有变化的键被省略
+func mergeChanges(first, second []Change) []Change {
+	type span struct {
+		old, new       string
+		hadOld, hasNew bool
+	}
+	spans := make(map[string]*span)
+	var order []string
+	for _, c := range append(append([]Change(nil), first...), second...) {
+		sp, ok := spans[c.Key]
+		if !ok {
+			sp = &span{old: c.Old, hadOld: c.Type != Added}
+			spans[c.Key] = sp
+			order = append(order, c.Key)
+		}
+		sp.new, sp.hasNew = c.New, c.Type != Removed
+	}
+
+	var merged []Change
+	for _, key := range order {
+		sp := spans[key]
+		c := Change{Key: key, Old: sp.old, New: sp.new}
+		switch {
+		case !sp.hadOld && sp.hasNew:
+			c.Type, c.Old = Added, ""
+		case sp.hadOld && !sp.hasNew:
+			c.Type, c.New = Removed, ""
+		case sp.hadOld && sp.hasNew && sp.old != sp.new:
+			c.Type = Modified
+		default:
+			continue
+		}
+		merged = append(merged, c)
+	}
+	return merged
+}
+
+// Subscribe 订阅默认加载器的重载事件，参见 Loader.Subscribe；未初始化时返回 nil
+func Subscribe(buffer int, policy Backpressure) *Subscription {
+	if l := std.Load(); l != nil {
+		return l.Subscribe(buffer, policy)
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package loadenv
+
+import (
+	"crypto/tls"
+	"fmt"
+	"io/fs"
+	"sync"
+	"time"
+)
+
+// tlsRecheckInterval 两次检查证书文件是否变化的最小间隔
+const tlsRecheckInterval = time.Second
+
+// WatchTLS 返回一个 tls.Config，其 GetCertificate 总是返回最新的证书
+//
+// certKey 和 keyKey 是保存证书与私钥文件路径的键（如 TLS_CERT_FILE、TLS_KEY_FILE）。
+// 握手时检查键的值是否变化，并至多每秒检查一次文件的修改时间和大小，变化时重新加载证书；
+// 新证书加载失败时继续使用之前的证书并记录日志。文件通过 Config.FS 读取，检查间隔按 Config.Clock 计算
+func (l *Loader) WatchTLS(certKey, keyKey string) *tls.Config {
+	w := &tlsWatcher{certKey: certKey, keyKey: keyKey}
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			return w.certificate(l)
+		},
+	}
+}
+
+// WatchTLS 使用默认加载器，参见 Loader.WatchTLS；未初始化时直接从进程环境变量读取路径
+func WatchTLS(certKey, keyKey string) *tls.Config {
+	w := &tlsWatcher{certKey: certKey, keyKey: keyKey}
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			return w.certificate(std.Load())
+		},
+	}
+}
+
+// tlsFile 证书文件的路径及加载时的状态
+type tlsFile struct {
+	path  string
+	mtime time.Time
+	size  int64
+}
+
+func statTLSFile(fsys FS, path string) (tlsFile, error) {
+	fi, err := fsys.Stat(path)
+	if err != nil {
+		return tlsFile{}, err
+	}
+	return tlsFile{path: path, mtime: fi.ModTime(), size: fi.Size()}, nil
+}
+
+// tlsWatcher 缓存最近一次成功加载的证书
+type tlsWatcher struct {
+	certKey, keyKey string
+
+	mu        sync.Mutex
+	cert      *tls.Certificate
+	certFile  tlsFile
+	keyFile   tlsFile
+	lastCheck time.Time
+}
+
+// certificate 返回当前证书，l 为 nil 时从进程环境变量读取路径
+func (w *tlsWatcher) certificate(l *Loader) (*tls.Certificate, error) {
+	var snap *Snapshot
+	if l != nil {
+		snap = l.Snapshot()
+	}
+	return w.current(l, snap.Get(w.certKey), snap.Get(w.keyKey))
+}
+
+// current 返回 certPath 和 keyPath 对应的证书，必要时重新加载；
+// l 为 nil 时不记录日志，使用本地文件系统和真实时间
+func (w *tlsWatcher) current(l *Loader, certPath, keyPath string) (*tls.Certificate, error) {
+	var (
+		fsys  FS    = osFS{}
+		clock Clock = realClock{}
+	)
+	if l != nil {
+		fsys, clock = l.cfg.FS, l.cfg.Clock
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	now := clock.Now()
+	pathsChanged := certPath != w.certFile.path || keyPath != w.keyFile.path
+	if w.cert != nil && !pathsChanged && now.Sub(w.lastCheck) < tlsRecheckInterval {
+		return w.cert, nil
+	}
+	w.lastCheck = now
+
+	if err := w.reload(l, fsys, certPath, keyPath, pathsChanged); err != nil {
+		if w.cert == nil {
+			return nil, err
+		}
+		if l != nil {
+			l.logger.Printf("TLS certificate reload failed, keeping previous certificate: %v", err)
+		}
+	}
+	return w.cert, nil
+}
+
+// reload 在路径或文件状态变化时重新加载证书，调用方需持有 mu
+func (w *tlsWatcher) reload(l *Loader, fsys FS, certPath, keyPath string, force bool) error {
+	if certPath == "" || keyPath == "" {
+		return fmt.Errorf("%w: %s or %s", ErrNotSet, w.certKey, w.keyKey)
+	}
+	certFile, err := statTLSFile(fsys, certPath)
+	if err != nil {
+		return err
+	}
+	keyFile, err := statTLSFile(fsys, keyPath)
+	if err != nil {
+		return err
+	}
+	if !force && w.cert != nil && certFile == w.certFile && keyFile == w.keyFile {
+		return nil
+	}
+
+	certPEM, err := fs.ReadFile(fsys, certPath)
+	if err != nil {
+		return err
+	}
+	keyPEM, err := fs.ReadFile(fsys, keyPath)
+	if err != nil {
+		return err
+	}
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return err
+	}
+	w.cert, w.certFile, w.keyFile = &cert, certFile, keyFile
+	if l != nil {
+		l.logger.Printf("Loaded TLS certificate from %s", certPath)
+	}
+	return nil
+}
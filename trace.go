@@ -0,0 +1,29 @@
+package loadenv
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// trace 逐键记录本次加载的合并过程：每一层的候选值、胜出的一层及规则，敏感键的值隐藏
+//
+// 输出形如
+//
+//	Trace PORT: file:.env="8080" < remote="9090" => remote (sources override env files, and later sources override earlier ones)
+func (l *Loader) trace(snap *Snapshot) {
+	keys := make([]string, 0, len(snap.defs))
+	for key := range snap.defs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	l.logger.Printf("Trace: merged %d keys", len(keys))
+	for _, key := range keys {
+		e := snap.Explain(key)
+		candidates := make([]string, len(e.Definitions))
+		for i, d := range e.Definitions {
+			candidates[i] = d.Source + "=" + strconv.Quote(d.Value)
+		}
+		l.logger.Printf("Trace %s: %s => %s (%s)", key, strings.Join(candidates, " < "), e.Source, e.Reason)
+	}
+}
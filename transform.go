@@ -0,0 +1,44 @@
+package loadenv
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Transformer 加载时对每个值进行转换，返回错误时本次加载失败
+type Transformer func(key, value string) (string, error)
+
+// TrimSpace 去掉值首尾的空白
+func TrimSpace(key, value string) (string, error) {
+	return strings.TrimSpace(value), nil
+}
+
+// NormalizeBool 将常见的布尔写法（yes/no、on/off、y/n、1/0，不区分大小写）统一为 true/false，
+// 其他值保持不变
+func NormalizeBool(key, value string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "1", "t", "true", "y", "yes", "on":
+		return "true", nil
+	case "0", "f", "false", "n", "no", "off":
+		return "false", nil
+	}
+	return value, nil
+}
+
+// transform 依次对 env 中的每个值执行转换器，原地修改 env
+func transform(ts []Transformer, env map[string]string) error {
+	if len(ts) == 0 {
+		return nil
+	}
+	for key, value := range env {
+		for _, t := range ts {
+			v, err := t(key, value)
+			if err != nil {
+				return fmt.Errorf("loadenv: transform %s: %w", key, err)
+			}
+			value = v
+		}
+		env[key] = value
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package loadenv
+
+import (
+	"slices"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// 重建监听器的退避时间
+const (
+	minWatchBackoff = 100 * time.Millisecond
+	maxWatchBackoff = 30 * time.Second
+)
+
+// scheduleWatchCheck 在 WatchCheckInterval 之后向 check 投递一次自检信号
+func (l *Loader) scheduleWatchCheck(check chan<- struct{}) {
+	l.cfg.Clock.AfterFunc(l.cfg.WatchCheckInterval, func() {
+		select {
+		case check <- struct{}{}:
+		default:
+		}
+	})
+}
+
+// checkWatch 确认环境文件仍在监听列表中
+//
+// 文件被删除或被重命名替换后，fsnotify 会静默移除对它的监听；
+// 文件重新出现时重新添加监听，并按文件创建处理，以免错过期间的修改
+func (l *Loader) checkWatch() {
+	if slices.Contains(l.watcher.WatchList(), l.absPath) {
+		return
+	}
+	if err := l.watcher.Add(l.absPath); err != nil {
+		l.logger.Printf("Watcher self-check: %s is not watched: %v", l.absPath, err)
+		return
+	}
+	l.logger.Printf("Watcher self-check: re-added %s", l.absPath)
+	l.handleEvent(fsnotify.Event{Name: l.absPath, Op: fsnotify.Create})
+}
+
+// restartWatcher 在监听器的通道关闭后重建监听器，失败时按指数退避重试；
+// 加载器关闭时返回 false，调用方需在监听协程中调用
+func (l *Loader) restartWatcher() bool {
+	l.logger.Printf("Watcher stopped unexpectedly, restarting")
+	l.watcher.Close()
+
+	backoff := minWatchBackoff
+	for {
+		w, err := l.cfg.FS.Watch()
+		if err == nil {
+			l.watcher = w
+			l.stats.recordWatcherRestart()
+			l.logger.Printf("Watcher restarted for: %s", l.absPath)
+			// 文件暂时不存在时由自检稍后重新添加；监听中断期间可能错过了修改
+			if err := w.Add(l.absPath); err != nil {
+				l.logger.Printf("Watcher restart: %s is not watched: %v", l.absPath, err)
+			} else {
+				l.handleEvent(fsnotify.Event{Name: l.absPath, Op: fsnotify.Write})
+			}
+			return true
+		}
+		l.logger.Printf("Watcher restart failed: %v (retrying in %v)", err, backoff)
+		if !l.sleep(backoff) {
+			return false
+		}
+		backoff = min(backoff*2, maxWatchBackoff)
+	}
+}
+
+// sleep 按 Clock 等待 d，加载器关闭时提前返回 false
+func (l *Loader) sleep(d time.Duration) bool {
+	done := make(chan struct{})
+	t := l.cfg.Clock.AfterFunc(d, func() { close(done) })
+	select {
+	case <-done:
+		return true
+	case <-l.closeCh:
+		t.Stop()
+		return false
+	}
+}